@@ -0,0 +1,57 @@
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket activation.
+const listenFDsStart = 3
+
+// errNoActivatedSocket is returned when socket activation was requested but no matching socket was passed.
+var errNoActivatedSocket = errors.New("statsd: no socket passed by socket activation")
+
+// fileConn returns a connection using a duplicate of the file's descriptor.
+func fileConn(f *os.File) (net.Conn, error) {
+	conn, err := net.FileConn(f)
+	if err != nil {
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+
+	return conn, nil
+}
+
+// activatedConn returns a connection using a datagram socket passed by systemd socket
+// activation (LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES). When name is empty,
+// the first passed socket is used.
+func activatedConn(name string) (net.Conn, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, errNoActivatedSocket
+	}
+
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, errNoActivatedSocket
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	for i := range n {
+		if name != "" && (i >= len(names) || names[i] != name) {
+			continue
+		}
+
+		f := os.NewFile(uintptr(listenFDsStart+i), "statsd")
+
+		conn, err := fileConn(f)
+		_ = f.Close() // FileConn works on its own duplicate
+
+		return conn, err
+	}
+
+	return nil, fmt.Errorf("%w: %q", errNoActivatedSocket, name)
+}
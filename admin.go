@@ -0,0 +1,92 @@
+package statsd
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// adminHealth is the JSON form of SinkHealth.
+type adminHealth struct {
+	State               string     `json:"state"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorTime       *time.Time `json:"last_error_time,omitempty"`
+	LastSuccessTime     *time.Time `json:"last_success_time,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// AdminHandler returns a handler serving the state of the client as JSON, meant to be mounted
+// on an operations port: "/health" (Health), "/config" (Config), "/stats" (Stats),
+// "/instruments" (Snapshot) and "/" with all of them.
+//
+//	mux.Handle("/statsd/", http.StripPrefix("/statsd", client.AdminHandler()))
+func (c *Client) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, c.adminHealth())
+	})
+	mux.HandleFunc("GET /config", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, c.Config())
+	})
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, c.Stats())
+	})
+	mux.HandleFunc("GET /instruments", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, c.Snapshot())
+	})
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{
+			"health":      c.adminHealth(),
+			"config":      c.Config(),
+			"stats":       c.Stats(),
+			"instruments": c.Snapshot(),
+		})
+	})
+
+	return mux
+}
+
+// adminHealth returns Health in its JSON form.
+func (c *Client) adminHealth() map[string]adminHealth {
+	sinks := c.Health()
+	result := make(map[string]adminHealth, len(sinks))
+
+	for name, h := range sinks {
+		lastError := ""
+		if h.LastError != nil {
+			lastError = h.LastError.Error()
+		}
+
+		result[name] = adminHealth{
+			State:               h.State.String(),
+			LastError:           lastError,
+			LastErrorTime:       optionalTime(h.LastErrorTime),
+			LastSuccessTime:     optionalTime(h.LastSuccessTime),
+			ConsecutiveFailures: h.ConsecutiveFailures,
+		}
+	}
+
+	return result
+}
+
+// optionalTime returns a pointer to t, or nil if t is zero.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// writeJSON writes the value as a JSON response.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,177 @@
+package statsd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// adminSocketMode restricts the admin socket to the user running the process.
+const adminSocketMode = 0o600
+
+// adminIdleTimeout closes admin connections that send no command for a while.
+const adminIdleTimeout = time.Minute
+
+// adminUsage lists the commands of the admin socket.
+const adminUsage = `commands:
+  rate PATTERN RATE   sample metrics matching PATTERN at RATE, e.g. "rate cache.* 0.01"
+  rate reset          restore the configured sample rates
+  drop PATTERN        drop metrics matching PATTERN
+  drop reset          send all metrics again
+  debug LEVEL         set the level of debug metrics
+  enable | disable    enable or disable the client
+  stats | config      print the stats or the configuration as JSON
+  help                print this message`
+
+// Errors of malformed admin commands.
+var (
+	errAdminCommand  = errors.New("unknown command or arguments, try help")
+	errAdminArgument = errors.New("invalid argument")
+)
+
+// ListenAdminSocket listens on a Unix socket at the path for ServeAdmin, accessible only by the user
+// running the process, so file permissions protect it.
+func ListenAdminSocket(path string) (net.Listener, error) {
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+
+	if err := os.Chmod(path, adminSocketMode); err != nil {
+		ln.Close() //nolint:errcheck
+
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+
+	return ln, nil
+}
+
+// ServeAdmin accepts connections on the listener, e.g. one returned by ListenAdminSocket, until ctx
+// is done, and executes the text commands they send, one per line, for live mitigation of metric storms:
+//
+//	$ echo "drop http.client.*" | socat - UNIX-CONNECT:/run/app/statsd.sock
+//	ok
+//
+// Commands change sample rates (SetSampleRates), drop metrics (DropMetrics), set the debug level,
+// toggle the client and print its stats; "help" lists them. The listener is closed on return.
+// Protect TCP listeners, which have no file permissions, e.g. by binding them to localhost.
+// It returns the context's error, or the error of the listener if it fails.
+func (c *Client) ServeAdmin(ctx context.Context, ln net.Listener) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	stop := context.AfterFunc(ctx, func() {
+		ln.Close() //nolint:errcheck
+	})
+	defer stop()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("statsd: %w", ctx.Err())
+			}
+
+			ln.Close() //nolint:errcheck
+
+			return fmt.Errorf("statsd: %w", err)
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			c.serveAdminConn(ctx, conn)
+		}()
+	}
+}
+
+// serveAdminConn executes the commands of a connection until it is closed or ctx is done.
+func (c *Client) serveAdminConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close() //nolint:errcheck
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close() //nolint:errcheck
+	})
+	defer stop()
+
+	scanner := bufio.NewScanner(conn)
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(adminIdleTimeout)) //nolint:errcheck
+
+		if !scanner.Scan() {
+			return
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if err := c.adminCommand(conn, strings.Fields(line)); err != nil {
+			fmt.Fprintf(conn, "error: %s\n", err)
+		}
+	}
+}
+
+// adminCommand executes an admin command, writing its output to w.
+func (c *Client) adminCommand(w io.Writer, args []string) error {
+	switch {
+	case len(args) == 3 && args[0] == "rate":
+		rate, err := strconv.ParseFloat(args[2], 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return fmt.Errorf("%w: rate %q", errAdminArgument, args[2])
+		}
+
+		rates := maps.Clone(c.sampleRates.Load().patterns)
+		if rates == nil {
+			rates = make(map[string]float64)
+		}
+
+		rates[args[1]] = rate
+		c.SetSampleRates(rates)
+	case len(args) == 2 && args[0] == "rate" && args[1] == "reset":
+		c.SetSampleRates(nil)
+	case len(args) == 2 && args[0] == "drop" && args[1] == "reset":
+		c.DropMetrics()
+	case len(args) == 2 && args[0] == "drop":
+		c.DropMetrics(append(c.DroppedMetrics(), args[1])...)
+	case len(args) == 2 && args[0] == "debug":
+		level, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("%w: level %q", errAdminArgument, args[1])
+		}
+
+		c.SetDebugLevel(level)
+	case len(args) == 1 && args[0] == "enable":
+		c.Enable()
+	case len(args) == 1 && args[0] == "disable":
+		c.Disable()
+	case len(args) == 1 && args[0] == "stats":
+		return json.NewEncoder(w).Encode(c.Stats()) //nolint:wrapcheck
+	case len(args) == 1 && args[0] == "config":
+		return json.NewEncoder(w).Encode(c.Config()) //nolint:wrapcheck
+	case len(args) == 1 && args[0] == "help":
+		_, err := fmt.Fprintln(w, adminUsage)
+
+		return err //nolint:wrapcheck
+	default:
+		return errAdminCommand
+	}
+
+	_, err := fmt.Fprintln(w, "ok")
+
+	return err //nolint:wrapcheck
+}
@@ -0,0 +1,51 @@
+package statsd
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+)
+
+// endpoint is an address a StatsD agent may listen on.
+type endpoint struct {
+	network string
+	address string
+}
+
+// endpoints returns the endpoints probed by AutoDetect, in order.
+func endpoints() []endpoint {
+	var candidates []endpoint
+
+	if path := os.Getenv("DD_DOGSTATSD_SOCKET"); path != "" {
+		candidates = append(candidates, endpoint{network: "unixgram", address: path})
+	}
+
+	if host := os.Getenv("DD_AGENT_HOST"); host != "" {
+		candidates = append(candidates, endpoint{network: "udp", address: net.JoinHostPort(host, strconv.Itoa(defaultPort))})
+	}
+
+	return append(candidates, endpoint{network: "udp", address: net.JoinHostPort("localhost", strconv.Itoa(defaultPort))})
+}
+
+// detect dials the first endpoint that accepts a probe, or the last endpoint if none does.
+func detect() (net.Conn, error) {
+	candidates := endpoints()
+
+	for _, e := range candidates[:len(candidates)-1] {
+		conn, err := dialNetwork(e.network, e.address)
+		if err != nil {
+			continue
+		}
+
+		if probe(context.Background(), conn) == nil {
+			return conn, nil
+		}
+
+		conn.Close() //nolint:errcheck
+	}
+
+	last := candidates[len(candidates)-1]
+
+	return dialNetwork(last.network, last.address)
+}
@@ -0,0 +1,59 @@
+package statsd
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+// modulePath is the path of this module in the build information of binaries using it.
+const modulePath = "github.com/devem-tech/statsd"
+
+// Tags of the startup counter and the configuration gauge.
+const (
+	TagLibraryVersion = "statsd_version"
+	TagConfigHash     = "config_hash"
+	TagHost           = "host"
+	TagTarget         = "target"
+)
+
+// Version returns the version of this library in the running binary, e.g. "v1.4.0", "(devel)" when
+// built within its own module, or "unknown" if the build information isn't available.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path != modulePath {
+			continue
+		}
+
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+
+		return dep.Version
+	}
+
+	return "unknown"
+}
+
+// sendStart sends the startup counter with the key once, tagged with the library version,
+// the configuration hash and the host name.
+func (c *Client) sendStart(key string) {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	c.Increment(key,
+		Tag{Key: TagLibraryVersion, Value: Version()},
+		Tag{Key: TagConfigHash, Value: c.config.Hash()},
+		Tag{Key: TagHost, Value: host},
+	)
+}
@@ -0,0 +1,105 @@
+package statsd
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// batchingLimit is the number of buffered bytes that makes a batching connection write right away.
+const batchingLimit = 64 << 10
+
+// batchingConn holds the payloads written to a stream sink for up to a delay and writes them
+// together, like Nagle's algorithm, so many small flushes result in few writes.
+type batchingConn struct {
+	io.WriteCloser
+
+	lock      sync.Mutex
+	buf       []byte
+	separator []byte // Inserted between payloads, unless they end with a separator
+	delay     time.Duration
+	timer     *time.Timer
+	onError   func(error)
+}
+
+// newBatchingConn returns a connection batching the payloads written to conn.
+func newBatchingConn(conn io.WriteCloser, delay time.Duration, separator []byte, onError func(error)) *batchingConn {
+	return &batchingConn{
+		WriteCloser: conn,
+		lock:        sync.Mutex{},
+		buf:         nil,
+		separator:   separator,
+		delay:       delay,
+		timer:       nil,
+		onError:     onError,
+	}
+}
+
+// Write buffers the payload, writing the buffer if it is large enough.
+func (b *batchingConn) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.buf) > 0 {
+		b.buf = append(b.buf, b.separator...)
+	}
+
+	b.buf = append(b.buf, p...)
+
+	if len(b.buf) >= batchingLimit {
+		return len(p), b.flushLocked()
+	}
+
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.delay, b.flushDelayed)
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the buffered payloads.
+func (b *batchingConn) Flush() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.flushLocked()
+}
+
+// Close writes the buffered payloads and closes the connection.
+func (b *batchingConn) Close() error {
+	if err := b.Flush(); err != nil {
+		b.WriteCloser.Close() //nolint:errcheck
+
+		return err
+	}
+
+	return b.WriteCloser.Close() //nolint:wrapcheck
+}
+
+// flushDelayed writes the buffered payloads once the delay passed.
+func (b *batchingConn) flushDelayed() {
+	if err := b.Flush(); err != nil {
+		b.onError(err)
+	}
+}
+
+// flushLocked writes the buffered payloads. The lock must be held.
+func (b *batchingConn) flushLocked() error {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	if len(b.buf) == 0 {
+		return nil
+	}
+
+	_, err := b.WriteCloser.Write(b.buf)
+	b.buf = b.buf[:0]
+
+	if err != nil {
+		return connError(err)
+	}
+
+	return nil
+}
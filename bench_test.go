@@ -0,0 +1,68 @@
+package statsd_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/devem-tech/statsd"
+	"github.com/devem-tech/statsd/statsdtest"
+)
+
+// benchBatch is the number of metrics emitted per flush by the client benchmarks.
+const benchBatch = 1000
+
+// BenchmarkClient measures the cost of the client per metric for buffer sizes and gauge aggregation,
+// reporting the payloads written and their size.
+func BenchmarkClient(b *testing.B) {
+	for _, size := range []int{512, 1432, 8192} {
+		for _, aggregate := range []bool{false, true} {
+			name := "buffer=" + strconv.Itoa(size) + "/aggregate=" + strconv.FormatBool(aggregate)
+
+			b.Run(name, func(b *testing.B) {
+				var sink statsdtest.NullSink
+
+				client, flush := newTickedClient(b, &sink, statsd.MaxBufferSize(size), statsd.AggregateGauges(aggregate))
+				gen := statsdtest.NewGenerator(100, 20, 2)
+
+				b.ReportAllocs()
+
+				for range b.N {
+					gen.EmitN(client, benchBatch)
+					flush()
+				}
+
+				reportSink(b, &sink, b.N*benchBatch)
+			})
+		}
+	}
+}
+
+// BenchmarkClientParallel measures the client sending from many goroutines, flushed in the background.
+func BenchmarkClientParallel(b *testing.B) {
+	var sink statsdtest.NullSink
+
+	client, err := statsd.New(statsd.Writer(&sink))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		gen := statsdtest.NewGenerator(100, 20, 2)
+
+		for pb.Next() {
+			gen.Emit(client)
+		}
+	})
+
+	client.Close()
+	reportSink(b, &sink, b.N)
+}
+
+// reportSink reports the payloads written to the sink and their bytes per metric emitted.
+func reportSink(b *testing.B, sink *statsdtest.NullSink, metrics int) {
+	b.Helper()
+
+	b.ReportMetric(float64(sink.Writes())/float64(metrics), "writes/metric")
+	b.ReportMetric(float64(sink.Bytes())/float64(metrics), "bytes/metric")
+}
@@ -0,0 +1,30 @@
+package statsd
+
+import "runtime/debug"
+
+// BuildInfoTags returns tags describing the running binary, read from its build information:
+// the main module path ("module") and version ("version"), and, if the binary was built from
+// a VCS checkout, the revision ("revision") and whether the tree had local changes ("dirty").
+// It returns nil if the build information isn't available.
+func BuildInfoTags() []Tag {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	tags := []Tag{
+		{Key: "module", Value: info.Main.Path},
+		{Key: "version", Value: info.Main.Version},
+	}
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			tags = append(tags, Tag{Key: "revision", Value: setting.Value})
+		case "vcs.modified":
+			tags = append(tags, Tag{Key: "dirty", Value: setting.Value})
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,103 @@
+package statsd
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// callbackQueueSize is the number of error handler calls an isolated client queues before dropping them.
+const callbackQueueSize = 64
+
+// callbacks runs user-supplied callbacks isolated from the flusher: error handler calls are queued to a
+// worker goroutine, and all calls recover from panics and are timed against a budget. See the
+// IsolateCallbacks option.
+type callbacks struct {
+	calls   chan func()
+	budget  time.Duration
+	logger  logger
+	slow    *atomic.Uint64
+	dropped *atomic.Uint64
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+// newCallbacks returns a running callback worker counting slow and dropped calls in the stats.
+func newCallbacks(budget time.Duration, l logger, s *stats) *callbacks {
+	cb := &callbacks{
+		calls:   make(chan func(), callbackQueueSize),
+		budget:  budget,
+		logger:  l,
+		slow:    &s.slowCallbacks,
+		dropped: &s.droppedCallbacks,
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go cb.run()
+
+	return cb
+}
+
+// run calls the queued callbacks until the worker is stopped, then calls the remaining ones.
+func (cb *callbacks) run() {
+	defer close(cb.done)
+
+	for {
+		select {
+		case fn := <-cb.calls:
+			cb.call("error handler", "", fn)
+		case <-cb.quit:
+			for {
+				select {
+				case fn := <-cb.calls:
+					cb.call("error handler", "", fn)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// submit queues the callback to the worker without blocking, dropping it if the queue is full.
+func (cb *callbacks) submit(fn func()) {
+	select {
+	case cb.calls <- fn:
+	default:
+		cb.dropped.Add(1)
+	}
+}
+
+// call calls the callback, recovering from its panics and counting it as slow if it exceeded the budget.
+// It reports false if the callback panicked. The kind and key describe the callback in log messages.
+func (cb *callbacks) call(kind, key string, fn func()) (ok bool) {
+	start := time.Now()
+
+	defer func() {
+		if v := recover(); v != nil {
+			ok = false
+
+			cb.slow.Add(1)
+			cb.logger.log(slog.LevelError, kind+" panicked", slog.String("key", key), slog.Any("panic", v))
+		} else if elapsed := time.Since(start); elapsed > cb.budget {
+			cb.slow.Add(1)
+			cb.logger.log(slog.LevelWarn, kind+" exceeded its time budget", slog.String("key", key),
+				slog.Duration("elapsed", elapsed), slog.Duration("budget", cb.budget))
+		}
+	}()
+
+	fn()
+
+	return true
+}
+
+// stop stops the worker, waiting at most the budget for the queued callbacks to be called.
+func (cb *callbacks) stop() {
+	close(cb.quit)
+
+	select {
+	case <-cb.done:
+	case <-time.After(cb.budget):
+	}
+}
@@ -0,0 +1,142 @@
+package statsd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// errMalformedCapture is returned by Replay when a captured record cannot be parsed.
+var errMalformedCapture = errors.New("statsd: malformed capture record")
+
+// captureWriter records every payload written to it with the time it was written.
+type captureWriter struct {
+	w    io.Writer
+	now  func() time.Time
+	buf  []byte
+	lock sync.Mutex
+}
+
+// Capture returns a writer to be used with the Writer option that records the serialized
+// output stream to w. Every line is stored as "<unix nanoseconds> <line>", and lines
+// flushed together share the same timestamp, so Replay can restore both the original
+// payloads and the relative timing between them.
+func Capture(w io.Writer) io.Writer {
+	return &captureWriter{
+		w:    w,
+		now:  time.Now,
+		buf:  nil,
+		lock: sync.Mutex{},
+	}
+}
+
+// Write records a single payload.
+func (c *captureWriter) Write(p []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ts := strconv.AppendInt(nil, c.now().UnixNano(), 10)
+
+	c.buf = c.buf[:0]
+
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+
+		c.buf = append(c.buf, ts...)
+		c.buf = append(c.buf, ' ')
+		c.buf = append(c.buf, line...)
+		c.buf = append(c.buf, '\n')
+	}
+
+	_, err := c.w.Write(c.buf)
+	if err != nil {
+		return 0, fmt.Errorf("statsd: %w", err)
+	}
+
+	return len(p), nil
+}
+
+// Replay reads a stream recorded by Capture and writes its payloads to w,
+// waiting between them as long as passed between the original flushes.
+// It stops early with the context's error if ctx is done.
+func Replay(ctx context.Context, r io.Reader, w io.Writer) error {
+	var (
+		payload []byte
+		prevTS  int64
+		started bool
+	)
+
+	flush := func() error {
+		if len(payload) == 0 {
+			return nil
+		}
+
+		_, err := w.Write(payload[:len(payload)-1])
+		payload = payload[:0]
+
+		if err != nil {
+			return fmt.Errorf("statsd: %w", err)
+		}
+
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		ts, line, ok := bytes.Cut(scanner.Bytes(), []byte{' '})
+		if !ok {
+			return errMalformedCapture
+		}
+
+		nanos, err := strconv.ParseInt(string(ts), 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errMalformedCapture, err)
+		}
+
+		if started && nanos != prevTS {
+			if err := flush(); err != nil {
+				return err
+			}
+
+			if err := sleep(ctx, time.Duration(nanos-prevTS)); err != nil {
+				return err
+			}
+		}
+
+		started = true
+		prevTS = nanos
+		payload = append(payload, line...)
+		payload = append(payload, '\n')
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("statsd: %w", err)
+	}
+
+	return flush()
+}
+
+// sleep pauses for d or until ctx is done, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("statsd: %w", ctx.Err())
+	}
+}
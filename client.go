@@ -1,10 +1,19 @@
 package statsd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
 	"net"
+	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,13 +27,71 @@ const bufferCapFactor = 2
 
 // options represent the client configuration.
 type options struct {
-	host          string
-	port          int
-	maxBufferSize int
-	flushInterval time.Duration
-	errorHandler  func(error)
-	prefix        string
-	tags          []Tag
+	host              string
+	port              int
+	maxBufferSize     int
+	flushInterval     time.Duration
+	errorHandler      func(error)
+	prefix            string
+	tags              []Tag
+	writer            io.Writer
+	reconnect         bool
+	file              *os.File
+	activation        bool
+	socketName        string
+	pacing            time.Duration
+	coalesce          bool
+	instrumentTTL     int
+	separator         string
+	trailingSeparator bool
+	maxLineLength     int
+	linePolicy        LinePolicy
+	sanitization      Sanitization
+	tagFormat         TagFormat
+	dropTags          bool
+	nameTags          []string
+	timingUnit        time.Duration
+	timingType        TimingType
+	sourceHost        string
+	onClose           func(Stats)
+	retries           int
+	retryBackoff      time.Duration
+	pooling           bool
+	scheduler         *scheduler
+	errorChannelSize  int
+	logger            *slog.Logger
+	disabled          bool
+	reservoirSize     int
+	typePrefixes      TypeNames
+	typeSuffixes      TypeNames
+	stallTimeout      time.Duration
+	maxQueueSize      int
+	debugSequence     string
+	namespaceQuota    int
+	extendedTypes     bool
+	unbuffered        bool
+	batchKey          string
+	errorMetrics      int
+	autoDetect        bool
+	sampleRates       map[string]float64
+	syncOnClose       bool
+	syncTimeout       time.Duration
+	definitions       []MetricDefinition
+	writeBatching     time.Duration
+	errorCounter      string
+	callbackBudget    time.Duration
+	randSource        rand.Source
+	retainLimit       int
+	priorityReserve   int
+	priorityPatterns  []string
+	debugLevel        int
+	debugBudget       int
+	callerDriven      bool
+	reservedTags      []string
+	sink              Sink
+	mirrors           []*mirror
+	startCounter      string
+	aggregateGauges   bool
 }
 
 // Tag represents a key-value pair used for tagging metrics.
@@ -35,149 +102,736 @@ type Tag struct {
 
 // Client represents a StatsD client.
 type Client struct {
-	conn          net.Conn
+	conn          io.WriteCloser
+	connLock      sync.RWMutex
+	addr          string
+	reconnect     bool
+	queue         *queue
+	spare         *queue
+	queueLock     sync.Mutex
+	flushLock     sync.Mutex // Serializes flushes, which share the buffers below
 	buffer        []byte
-	bufferLock    sync.Mutex
 	maxBufferSize int
 	flushInterval time.Duration
-	flushChan     chan struct{}
-	quitChan      chan struct{}
-	wg            sync.WaitGroup
+	pacing        time.Duration
+	coalesce      bool
+	retries       int
+	retryBackoff  time.Duration
+	pooling       bool
+	scheduler     *scheduler
+	ownScheduler  bool
+	flushPending  atomic.Bool
+	disabled      atomic.Bool
+	watchdog      *watchdog
 	errorHandler  func(error)
-	prefix        []byte
-	tags          []byte
+	errors        chan error
+	logger        logger
+	onClose       func(Stats)
+	stats         stats
+	serializer    *serializer
+	frame         []byte
+	debugLine     []byte
+	debugBuf      []byte
+	instruments   *instruments
+	namespaces    *namespaces
+	extendedTypes bool
+	unbuffered    bool
+	batchKey      string
+	batchTags     []Tag // Tags of the metric being flushed plus the batch tag
+	config        Config
+	errorMetrics  int
+	autoDetect    bool
+	sampleRates   atomic.Pointer[sampleRates]
+	filter        atomic.Pointer[metricFilter]
+	syncOnClose   bool
+	syncTimeout   time.Duration
+	definitions   definitions
+	health        health
+	sinkStats     sinkStats // Totals of writes to the destination of the client
+	pressure      pressure
+	errorCounter  *errorCounter
+	callbacks     *callbacks
+	rand          *rand.Rand
+	retained      []byte // Lines of failed datagrams to write with the next flush
+	retainLimit   int
+	priorities    *priorities
+	debug         debugMetrics
+	reservedTags  []string
+	uniques       uniques
+	opts          []Option // Options the client was created with, for Clone
+	lastTick      atomic.Int64
+	encodings     []*encoding // Lines of the flush in the tag formats of the mirrors
+	gauges        *gaugeCache
+	inflight      inflight
 }
 
 // New returns a new Client.
 func New(opts ...Option) (*Client, error) {
-	o := &options{
-		host:          "",
-		port:          defaultPort,
-		maxBufferSize: defaultMaxBufferSize,
-		flushInterval: defaultFlushInterval,
-		errorHandler:  nil,
-		prefix:        "",
-		tags:          nil,
-	}
+	o := newOptions(opts...)
 
-	for _, opt := range opts {
-		opt(o)
-	}
-
-	conn, err := net.Dial("udp", o.host+":"+strconv.Itoa(o.port))
+	conn, err := dial(o)
 	if err != nil {
-		return nil, fmt.Errorf("statsd: %w", err)
+		return nil, err
 	}
 
 	client := &Client{
 		conn:          conn,
-		buffer:        make([]byte, 0, o.maxBufferSize*bufferCapFactor),
-		bufferLock:    sync.Mutex{},
+		connLock:      sync.RWMutex{},
+		addr:          address(o),
+		reconnect:     (o.reconnect || o.autoDetect) && o.dials(),
+		queue:         nil,
+		spare:         nil,
+		queueLock:     sync.Mutex{},
+		flushLock:     sync.Mutex{},
+		buffer:        nil,
 		maxBufferSize: o.maxBufferSize,
 		flushInterval: o.flushInterval,
-		flushChan:     make(chan struct{}, 1), // Buffer by 1 to prevent locks
-		quitChan:      make(chan struct{}),
-		wg:            sync.WaitGroup{},
+		pacing:        o.pacing,
+		coalesce:      o.coalesce,
+		retries:       o.retries,
+		retryBackoff:  o.retryBackoff,
+		pooling:       o.pooling,
+		scheduler:     o.scheduler,
+		ownScheduler:  o.scheduler == nil,
+		flushPending:  atomic.Bool{},
+		disabled:      atomic.Bool{},
+		watchdog:      nil,
 		errorHandler:  o.errorHandler,
-		prefix:        []byte(o.prefix),
-		tags:          make([]byte, 0, o.maxBufferSize),
+		errors:        nil,
+		logger:        logger{l: o.logger},
+		onClose:       o.onClose,
+		stats:         stats{},
+		serializer:    newSerializer(o),
+		frame:         nil,
+		debugLine:     nil,
+		debugBuf:      nil,
+		instruments:   newInstruments(o.instrumentTTL, o.reservoirSize),
+		namespaces:    newNamespaces(o.namespaceQuota),
+		extendedTypes: o.extendedTypes,
+		unbuffered:    o.unbuffered,
+		batchKey:      o.batchKey,
+		batchTags:     nil,
+		config:        o.config(),
+		errorMetrics:  o.errorMetrics,
+		autoDetect:    o.autoDetect,
+		sampleRates:   atomic.Pointer[sampleRates]{},
+		filter:        atomic.Pointer[metricFilter]{},
+		syncOnClose:   o.syncOnClose,
+		syncTimeout:   o.syncTimeout,
+		definitions:   newDefinitions(o.definitions),
+		health:        newHealth(),
+		sinkStats:     sinkStats{},
+		pressure:      pressure{metrics: 0, dropped: 0, dropRatio: atomic.Uint64{}},
+		errorCounter:  newErrorCounter(o.errorCounter),
+		callbacks:     nil,
+		rand:          rand.New(o.randSource), //nolint:gosec
+		retained:      nil,
+		retainLimit:   o.retainLimit,
+		priorities:    newPriorities(o.priorityReserve, o.priorityPatterns),
+		debug:         debugMetrics{level: atomic.Int32{}, budget: int64(o.debugBudget), sent: atomic.Int64{}},
+		reservedTags:  o.reservedTags,
+		uniques:       uniques{lock: sync.Mutex{}, expires: nil, size: 0},
+		opts:          slices.Clone(opts),
+		lastTick:      atomic.Int64{},
+		encodings:     newEncodings(o),
+		gauges:        nil,
+		inflight:      inflight{lines: atomic.Int64{}, bytes: atomic.Int64{}},
+	}
+
+	if nc, ok := conn.(net.Conn); ok && o.autoDetect {
+		client.addr = nc.RemoteAddr().String()
+	}
+
+	if (o.writer != nil || o.sink != nil) && o.writeBatching > 0 {
+		var separator []byte
+		if !o.trailingSeparator {
+			separator = []byte(o.separator)
+		}
+
+		client.conn = newBatchingConn(conn, o.writeBatching, separator, client.reportError)
+	}
+
+	client.queue = newQueue(client.serializer.overhead())
+	client.spare = newQueue(client.serializer.overhead())
+
+	client.disabled.Store(o.disabled)
+	client.sampleRates.Store(newSampleRates(o.sampleRates))
+
+	if o.aggregateGauges && !o.unbuffered {
+		client.gauges = newGaugeCache(o.flushInterval)
+	}
+	client.SetDebugLevel(o.debugLevel)
+
+	if o.debugSequence != "" {
+		line, _ := client.serializer.appendLine(nil, "", o.debugSequence, intValue(1), "c", nil)
+		client.debugLine = line[:len(line)-1]
+	}
+
+	if o.callbackBudget > 0 {
+		client.callbacks = newCallbacks(o.callbackBudget, client.logger, &client.stats)
+	}
+
+	if o.errorChannelSize > 0 {
+		client.errors = make(chan error, o.errorChannelSize)
+	}
+
+	if o.stallTimeout > 0 {
+		client.watchdog = newWatchdog(o.stallTimeout, o.maxQueueSize)
+	}
+
+	if o.startCounter != "" {
+		client.sendStart(o.startCounter)
+	}
+
+	// Caller-driven clients are flushed and watched by Tick instead
+	if o.callerDriven {
+		client.scheduler, client.ownScheduler = nil, false
+
+		return client, nil
+	}
+
+	if client.watchdog != nil {
+		client.watchdog.start(client)
 	}
 
-	client.serializeTagsTo(client.tags, o.tags)
-	client.startBackgroundFlusher()
+	if client.ownScheduler {
+		client.scheduler = newScheduler(o.flushInterval)
+	}
+
+	client.scheduler.add(client)
 
 	return client, nil
 }
 
-// startBackgroundFlusher starts the background flusher to send metrics regularly.
-func (c *Client) startBackgroundFlusher() {
-	c.wg.Add(1)
+// newOptions returns the default options with the options applied and validated.
+func newOptions(opts ...Option) *options {
+	o := &options{
+		host:              "",
+		port:              defaultPort,
+		maxBufferSize:     defaultMaxBufferSize,
+		flushInterval:     defaultFlushInterval,
+		errorHandler:      nil,
+		prefix:            "",
+		tags:              nil,
+		writer:            nil,
+		reconnect:         false,
+		file:              nil,
+		activation:        false,
+		socketName:        "",
+		pacing:            0,
+		coalesce:          false,
+		instrumentTTL:     0,
+		separator:         defaultSeparator,
+		trailingSeparator: false,
+		maxLineLength:     0,
+		linePolicy:        TruncateLine,
+		sanitization:      SanitizeNone,
+		tagFormat:         GraphiteTags,
+		dropTags:          false,
+		nameTags:          nil,
+		timingUnit:        time.Millisecond,
+		timingType:        TimerType,
+		sourceHost:        "",
+		onClose:           nil,
+		retries:           0,
+		retryBackoff:      0,
+		pooling:           true,
+		scheduler:         nil,
+		errorChannelSize:  0,
+		logger:            nil,
+		disabled:          false,
+		reservoirSize:     defaultReservoirSize,
+		typePrefixes:      TypeNames{Counter: "", Gauge: "", Timer: ""},
+		typeSuffixes:      TypeNames{Counter: "", Gauge: "", Timer: ""},
+		stallTimeout:      0,
+		maxQueueSize:      0,
+		debugSequence:     "",
+		namespaceQuota:    0,
+		extendedTypes:     false,
+		unbuffered:        false,
+		batchKey:          "",
+		errorMetrics:      0,
+		autoDetect:        false,
+		sampleRates:       nil,
+		syncOnClose:       false,
+		syncTimeout:       0,
+		definitions:       nil,
+		writeBatching:     0,
+		errorCounter:      "",
+		callbackBudget:    0,
+		randSource:        globalSource{},
+		retainLimit:       0,
+		priorityReserve:   0,
+		priorityPatterns:  nil,
+		debugLevel:        0,
+		debugBudget:       0,
+		callerDriven:      false,
+		reservedTags:      nil,
+		sink:              nil,
+		mirrors:           nil,
+		startCounter:      "",
+		aggregateGauges:   false,
+	}
 
-	go func() {
-		defer c.wg.Done()
+	for _, opt := range opts {
+		opt(o)
+	}
 
-		ticker := time.NewTicker(c.flushInterval)
-		defer ticker.Stop()
+	o.validate()
 
-		for {
-			select {
-			case <-ticker.C:
-				// Request flushing through the channel
-				c.requestFlush()
-			case <-c.flushChan:
-				// When the channel receives a signal, we flush the metrics
-				c.flushMetrics()
-			case <-c.quitChan:
-				// Closing, final flush
-				c.flushMetrics()
+	return o
+}
 
-				return
-			}
+// dial opens the connection the client writes its payloads to.
+func dial(o *options) (io.WriteCloser, error) {
+	switch {
+	case o.writer != nil:
+		return nopCloser{Writer: o.writer}, nil
+	case o.sink != nil:
+		return o.sink, nil
+	case o.file != nil:
+		return fileConn(o.file)
+	case o.activation:
+		return activatedConn(o.socketName)
+	case o.autoDetect:
+		return detect()
+	}
+
+	return dialNetwork("udp", address(o))
+}
+
+// dials reports whether the client dials the StatsD server itself rather than using a provided connection.
+func (o *options) dials() bool {
+	return o.writer == nil && o.sink == nil && o.file == nil && !o.activation
+}
+
+// address returns the StatsD server address.
+func address(o *options) string {
+	return net.JoinHostPort(o.host, strconv.Itoa(o.port))
+}
+
+// getConn returns the current connection.
+func (c *Client) getConn() io.WriteCloser {
+	c.connLock.RLock()
+	defer c.connLock.RUnlock()
+
+	return c.conn
+}
+
+// redial replaces the connection with a new one, resolving the server address again.
+func (c *Client) redial() error {
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	if c.autoDetect {
+		conn, err = detect()
+		if err != nil {
+			return err
 		}
-	}()
+	} else {
+		conn, err = dialNetwork("udp", c.addr)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.connLock.Lock()
+	old := c.conn
+	c.conn = conn
+	c.connLock.Unlock()
+
+	if err := old.Close(); err != nil {
+		return connError(err)
+	}
+
+	return nil
+}
+
+// nopCloser wraps a writer owned by the caller, so closing the client leaves it open.
+type nopCloser struct {
+	io.Writer
+}
+
+// Close does nothing.
+func (nopCloser) Close() error {
+	return nil
 }
 
-// requestFlush sends a signal for flushing through the channel.
+// requestFlush asks the background flusher to flush the client.
 func (c *Client) requestFlush() {
-	// Do not block if the flush is already
-	// in process (there is already a signal
-	// in the channel).
-	select {
-	case c.flushChan <- struct{}{}:
-	default:
-	}
-}
-
-// send adds the metric to the buffer instead of sending it immediately.
-func (c *Client) send(key, value, mt string, tags ...Tag) {
-	c.bufferLock.Lock()
-	defer c.bufferLock.Unlock()
-
-	c.buffer = append(c.buffer, c.prefix...)
-	c.buffer = append(c.buffer, key...)
-	c.buffer = append(c.buffer, ':')
-	c.buffer = append(c.buffer, value...)
-	c.buffer = append(c.buffer, c.tags...)
-	c.serializeTagsTo(c.buffer, tags)
-	c.buffer = append(c.buffer, '|')
-	c.buffer = append(c.buffer, mt...)
-	c.buffer = append(c.buffer, '\n')
-
-	// If the buffer is full, request flushing
-	if len(c.buffer) >= c.maxBufferSize {
+	if c.flushPending.CompareAndSwap(false, true) && c.scheduler != nil {
+		c.scheduler.notify()
+	}
+}
+
+// send queues the metric instead of sending it immediately.
+func (c *Client) send(key, mt string, v value, tags []Tag) {
+	c.sendTo(nil, key, mt, v, tags)
+}
+
+// sendTo queues the metric of the namespace, if any, instead of sending it immediately.
+func (c *Client) sendTo(ns *Namespace, key, mt string, v value, tags []Tag) {
+	if ns != nil && ns.tagPrefix != "" && len(tags) > 0 {
+		c.sendSandboxed(ns, key, mt, v, tags)
+
+		return
+	}
+
+	c.sendValues(ns, key, mt, tags, v)
+}
+
+// sendValues queues the values of the metric at once, so they are serialized consecutively.
+func (c *Client) sendValues(ns *Namespace, key, mt string, tags []Tag, values ...value) {
+	if c.disabled.Load() || c.filter.Load().drops(ns, key) {
+		return
+	}
+
+	tags = c.stripReserved(tags)
+
+	if c.definitions != nil {
+		if err := c.definitions.check(ns, key, mt, tags); err != nil {
+			c.stats.dropped.Add(uint64(len(values)))
+			c.reportError(err)
+
+			return
+		}
+	}
+
+	priority := c.priorities.high(ns, key)
+
+	if ns != nil && !priority && !c.namespaces.admit(ns) {
+		c.stats.dropped.Add(1)
+
+		return
+	}
+
+	if c.aggregate(ns, key, mt, tags, priority, values) {
+		return
+	}
+
+	c.enqueue(len(values), func(q *queue) int {
+		size := 0
+		for _, v := range values {
+			size = q.push(ns, key, mt, v, tags, priority)
+		}
+
+		return size
+	})
+}
+
+// enqueue adds n metrics to the queue in one locked operation with push, which returns
+// the new size of the queue, then requests flushing and sheds metrics as needed.
+func (c *Client) enqueue(n int, push func(q *queue) int) {
+	c.queueLock.Lock()
+
+	size := push(c.queue)
+
+	// If the buffer is full, request flushing. The flush takes the whole queue,
+	// including metrics queued after the request.
+	if size >= c.maxBufferSize {
 		c.requestFlush()
 	}
+
+	// If the flusher is stuck, shed the oldest metrics
+	dropped := 0
+	if c.watchdog != nil && c.watchdog.overflows(size) {
+		dropped = c.queue.dropOldest(c.watchdog.limit, c.priorities.reserve)
+	}
+
+	c.queueLock.Unlock()
+
+	c.stats.metrics.Add(uint64(n))
+
+	if c.unbuffered {
+		c.flushMetrics()
+	}
+
+	if dropped > 0 {
+		c.stats.dropped.Add(uint64(dropped))
+		c.logger.log(slog.LevelWarn, "dropped metrics of a stalled flusher", slog.Int("metrics", dropped))
+	}
 }
 
-// flushMetrics sends all metrics from the buffer to StatsD.
+// flushMetrics serializes the queued metrics and sends them to StatsD.
 func (c *Client) flushMetrics() {
-	c.bufferLock.Lock()
+	c.flushLock.Lock()
+	defer c.flushLock.Unlock()
+
+	if c.watchdog != nil {
+		defer c.watchdog.beat()
+	}
+
+	defer func() {
+		c.pressure.update(c.stats.metrics.Load(), c.stats.dropped.Load())
+	}()
+
+	if c.errorCounter != nil {
+		defer c.errorCounter.flush(c)
+	}
 
-	n := len(c.buffer)
-	if n == 0 {
-		c.bufferLock.Unlock()
+	if c.gauges != nil {
+		defer c.gauges.sweep()
+	}
+
+	c.queueLock.Lock()
+
+	if len(c.queue.metrics) == 0 {
+		c.queueLock.Unlock()
+		c.writeRetained()
 
 		return
 	}
 
-	data := c.buffer[:n-1]
-	c.buffer = c.buffer[:0]
-	c.bufferLock.Unlock()
+	// Swap the queues, so metrics can be sent while serializing and writing.
+	q := c.queue
+	c.queue = c.spare
+	c.namespaces.reset()
+	c.debug.reset()
+	c.queueLock.Unlock()
+
+	buf := c.getBuffer()
+	defer c.putBuffer(buf)
 
-	_, err := c.conn.Write(data)
-	if err != nil && c.errorHandler != nil {
-		c.errorHandler(err)
+	var batch Tag
+	if c.batchKey != "" {
+		batch = Tag{Key: c.batchKey, Value: strconv.FormatUint(uint64(rand.Uint32()), 16)} //nolint:gosec
 	}
+
+	for _, m := range q.metrics {
+		var err error
+
+		if m.gauge != nil {
+			m.value = m.gauge.flush()
+		}
+
+		tags := q.tags[m.tagsFrom:m.tagsTo]
+		if c.batchKey != "" {
+			c.batchTags = append(append(c.batchTags[:0], tags...), batch)
+			tags = c.batchTags
+		}
+
+		*buf, err = c.serializer.appendLine(*buf, m.namespace, m.key, m.value, m.mt, tags)
+		if err != nil {
+			c.stats.dropped.Add(1)
+			c.logger.log(slog.LevelWarn, "dropped metric", slog.String("key", m.key), slog.Any("error", err))
+			c.reportError(err)
+		}
+
+		for _, e := range c.encodings {
+			e.appendLine(m, tags)
+		}
+	}
+
+	q.reset()
+	c.spare = q
+	clear(c.batchTags) // Don't retain the tag strings
+
+	c.writeRetained()
+
+	var payload []byte
+	if n := len(*buf); n > 0 {
+		payload = (*buf)[:n-1]
+		if c.coalesce {
+			payload = coalesceCounters(payload)
+		}
+
+		c.inflight.add(payload)
+		c.writePayload(payload)
+	}
+
+	c.writeMirrors(payload)
+}
+
+// writePayload splits the serialized lines into datagrams and writes them.
+func (c *Client) writePayload(payload []byte) {
+	// Leave room for the debug line in every datagram
+	size := c.maxBufferSize
+	if c.debugLine != nil {
+		size -= len(c.debugLine) + 1
+	}
+
+	// Send every line on its own
+	if c.unbuffered {
+		size = 0
+	}
+
+	var failed []*WriteError
+
+	chunks := 0
+
+	for rest := payload; len(rest) > 0; chunks++ {
+		var datagram []byte
+
+		if len(rest) < len(payload) && c.pacing > 0 {
+			time.Sleep(c.pacing)
+		}
+
+		datagram, rest = splitDatagram(rest, size)
+		lines := datagram
+
+		if c.debugLine != nil {
+			c.debugBuf = append(append(append(c.debugBuf[:0], datagram...), '\n'), c.debugLine...)
+			datagram = c.debugBuf
+		}
+
+		if c.serializer.framed() {
+			c.frame = c.serializer.frame(c.frame[:0], datagram)
+			datagram = c.frame
+		}
+
+		err := c.write(datagram)
+		if err != nil {
+			// Only the lines not written by a partial write are lost
+			c.inflight.done(lines)
+			lines = unwritten(lines, err)
+
+			werr := newWriteError(err, lines, c.errorMetrics)
+			werr.Chunk = chunks
+			werr.Retained = c.retain(lines)
+
+			dropped := 0
+			if !werr.Retained {
+				dropped = werr.Lines
+			}
+
+			c.sinkStats.failed(dropped)
+
+			if werr.Retained {
+				c.inflight.add(lines)
+			} else {
+				c.stats.dropped.Add(uint64(werr.Lines))
+				c.logger.log(slog.LevelWarn, "dropped metrics", slog.Int("metrics", werr.Lines), slog.Any("error", err))
+			}
+
+			c.health.failure(err)
+
+			failed = append(failed, werr)
+
+			continue
+		}
+
+		c.inflight.done(lines)
+		c.stats.bytes.Add(uint64(len(datagram)))
+		c.stats.datagrams.Add(1)
+		c.sinkStats.written(datagram)
+		c.health.success()
+	}
+
+	// Report failures once the number of datagrams is known
+	for _, werr := range failed {
+		werr.Chunks = chunks
+		c.reportError(werr)
+	}
+}
+
+// retain keeps the lines of a failed datagram to write them with the next flush, if the
+// RetainFailedChunks option is set and they fit. It reports whether the lines were kept.
+func (c *Client) retain(lines []byte) bool {
+	if len(c.retained)+len(lines)+1 > c.retainLimit {
+		return false
+	}
+
+	if len(c.retained) > 0 {
+		c.retained = append(c.retained, '\n')
+	}
+
+	c.retained = append(c.retained, lines...)
+
+	return true
+}
+
+// writeRetained writes the lines retained from failed datagrams of previous flushes.
+func (c *Client) writeRetained() {
+	if len(c.retained) == 0 {
+		return
+	}
+
+	retained := c.retained
+	c.retained = nil
+
+	c.writePayload(retained)
+}
+
+// splitDatagram returns the leading whole lines of data that fit into size bytes and the remaining data.
+// A single line longer than size is returned on its own.
+func splitDatagram(data []byte, size int) ([]byte, []byte) {
+	if len(data) <= size {
+		return data, nil
+	}
+
+	i := bytes.LastIndexByte(data[:size+1], '\n')
+	if i < 0 {
+		i = bytes.IndexByte(data, '\n')
+		if i < 0 {
+			return data, nil
+		}
+	}
+
+	return data[:i], data[i+1:]
 }
 
-// serializeTagsTo serializes the tags into a byte slice.
-func (c *Client) serializeTagsTo(buffer []byte, tags []Tag) {
-	for _, tag := range tags {
-		buffer = append(buffer, ';')
-		buffer = append(buffer, tag.Key...)
-		buffer = append(buffer, '=')
-		buffer = append(buffer, tag.Value...)
+// write sends the payload, retrying it as configured if the write failed with a retryable error.
+func (c *Client) write(data []byte) error {
+	err := c.writeOnce(data)
+
+	backoff := c.retryBackoff
+
+	for retry := 0; err != nil && retry < c.retries && retryable(err); retry++ {
+		data = unwritten(data, err)
+
+		if backoff > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		err = c.writeOnce(data)
+	}
+
+	return err
+}
+
+// writeOnce sends the payload, reconnecting if enabled and the server refused it.
+func (c *Client) writeOnce(data []byte) error {
+	_, err := c.getConn().Write(data)
+	if err == nil {
+		return nil
+	}
+
+	err = connError(err)
+
+	if c.reconnect && errors.Is(err, ErrConnectionRefused) {
+		if redialErr := c.redial(); redialErr != nil {
+			c.logger.log(slog.LevelWarn, "reconnect failed", slog.String("addr", c.addr), slog.Any("error", redialErr))
+
+			return errors.Join(err, redialErr)
+		}
+
+		c.logger.log(slog.LevelInfo, "reconnected", slog.String("addr", c.addr))
 	}
+
+	return err
+}
+
+// Disable turns the client into a no-op: metrics are discarded until Enable is called.
+func (c *Client) Disable() {
+	c.disabled.Store(true)
+}
+
+// Enable makes the client send metrics again after Disable.
+func (c *Client) Enable() {
+	c.disabled.Store(false)
+}
+
+// Enabled reports whether the client sends metrics.
+func (c *Client) Enabled() bool {
+	return !c.disabled.Load()
+}
+
+// Prefix returns the effective prefix of metric names, without the trailing dot.
+func (c *Client) Prefix() string {
+	return strings.TrimSuffix(string(c.serializer.prefix), ".")
 }
 
 // Count sends a counter.
@@ -186,7 +840,7 @@ func (c *Client) Count(key string, value int64, tags ...Tag) {
 		return
 	}
 
-	c.send(key, strconv.FormatInt(value, 10), "c", tags...)
+	c.sendSampled(nil, key, "c", intValue(value), tags)
 }
 
 // Increment increases a counter by 1.
@@ -196,12 +850,38 @@ func (c *Client) Increment(key string, tags ...Tag) {
 
 // Gauge sends a gauge.
 func (c *Client) Gauge(key string, value float64, tags ...Tag) {
-	c.send(key, strconv.FormatFloat(value, 'f', -1, 64), "g", tags...)
+	c.sendSampled(nil, key, "g", floatValue(value), tags)
+}
+
+// GaugeAbsolute sets a gauge to value even if it is negative. StatsD reads a signed gauge value
+// as a change of the gauge, so negative values are sent as "key:0|g" followed by "key:-1|g"
+// in the same flush.
+func (c *Client) GaugeAbsolute(key string, value float64, tags ...Tag) {
+	if value >= 0 {
+		c.Gauge(key, value, tags...)
+
+		return
+	}
+
+	c.sendValues(nil, key, "g", tags, floatValue(0), floatValue(value))
+}
+
+// Set adds the value to a set, whose number of unique values per flush interval StatsD reports.
+// Values with unsafe bytes are dropped and reported as ErrInvalidName.
+func (c *Client) Set(key, value string, tags ...Tag) {
+	if !safeName(value) {
+		c.stats.dropped.Add(1)
+		c.reportError(fmt.Errorf("%w: set value %q", ErrInvalidName, value))
+
+		return
+	}
+
+	c.send(key, "s", stringValue(value), tags)
 }
 
 // Timing sends a timer.
 func (c *Client) Timing(key string, duration time.Duration, tags ...Tag) {
-	c.send(key, strconv.FormatInt(duration.Milliseconds(), 10), "ms", tags...)
+	c.sendSampled(nil, key, "ms", durationValue(duration), tags)
 }
 
 // Timer starts timing and sends the metric via defer.
@@ -215,12 +895,48 @@ func (c *Client) Timer(key string, tags ...Tag) func() {
 
 // Close closes the connection with StatsD and flushes the remaining metrics.
 func (c *Client) Close() {
-	close(c.quitChan)
+	if c.watchdog != nil {
+		c.watchdog.stop()
+	}
 
-	c.wg.Wait() // Wait for background tasks to finish
+	if c.scheduler != nil {
+		c.scheduler.remove(c) // Wait for background tasks to finish
+	}
+
+	if c.ownScheduler {
+		c.scheduler.stop()
+	}
+
+	// Final flush
+	c.instruments.collect(c)
+	c.flushMetrics()
+
+	if len(c.retained) > 0 {
+		c.stats.dropped.Add(uint64(bytes.Count(c.retained, []byte{'\n'}) + 1))
+	}
+
+	if c.syncOnClose {
+		if err := c.syncConn(); err != nil {
+			c.reportError(err)
+		}
+	}
+
+	err := c.getConn().Close()
+	if err != nil {
+		c.reportError(connError(err))
+	}
+
+	c.closeMirrors()
+
+	if c.callbacks != nil {
+		c.callbacks.stop()
+	}
+
+	if c.errors != nil {
+		close(c.errors)
+	}
 
-	err := c.conn.Close()
-	if err != nil && c.errorHandler != nil {
-		c.errorHandler(err)
+	if c.onClose != nil {
+		c.onClose(c.Stats())
 	}
 }
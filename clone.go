@@ -0,0 +1,63 @@
+package statsd
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrCloneTransport is returned by Clone when the client writes to a transport it owns and no destination
+// is passed for the clone.
+var ErrCloneTransport = errors.New("statsd: clone needs a destination of its own")
+
+// Clone returns a new client configured with the options of this one followed by opts, e.g. a new
+// sink, but with its own connection, buffers and background flusher, e.g. per test or per subprocess.
+// Options combine as in New, so a Prefix is appended to the original one. Clones of clients flushed
+// by a shared scheduler get their own flusher unless the Scheduler option is passed again.
+// Registered instruments aren't cloned.
+//
+// Transports the client owns and closes with itself aren't cloned, so closing the clone leaves the
+// client writing: the Output sink, File, activated socket and MirrorTo sinks are dropped from the options.
+// The clone of a client writing to an Output sink, File or activated socket needs a destination in opts,
+// e.g. Output with a sink of its own, Writer or Host, or Clone returns ErrCloneTransport. Writers
+// aren't closed by clients, so clones share them.
+func (c *Client) Clone(opts ...Option) (*Client, error) {
+	if appliedOptions(c.opts).ownsTransport() && !appliedOptions(opts).setsDestination() {
+		return nil, ErrCloneTransport
+	}
+
+	all := slices.Concat(c.opts, []Option{withScheduler(nil), withoutOwnedTransports()}, opts)
+
+	return New(all...)
+}
+
+// withoutOwnedTransports drops the transports closed by the client from the options.
+func withoutOwnedTransports() Option {
+	return func(o *options) {
+		o.sink = nil
+		o.file = nil
+		o.activation = false
+		o.socketName = ""
+		o.mirrors = nil
+	}
+}
+
+// appliedOptions returns the fields set by the options, without defaults.
+func appliedOptions(opts []Option) *options {
+	o := new(options)
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// ownsTransport reports whether the options make the client write to a transport it closes.
+func (o *options) ownsTransport() bool {
+	return o.sink != nil || o.file != nil || o.activation
+}
+
+// setsDestination reports whether the options set where the client writes to.
+func (o *options) setsDestination() bool {
+	return !o.dials() || o.autoDetect || o.host != "" || o.port != 0
+}
@@ -0,0 +1,88 @@
+package statsd_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/devem-tech/statsd"
+)
+
+// closingSink is a recordSink refusing writes once closed.
+type closingSink struct {
+	recordSink
+
+	closed bool
+}
+
+func (s *closingSink) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, os.ErrClosed
+	}
+
+	return s.recordSink.Write(p)
+}
+
+func (s *closingSink) Close() error {
+	s.closed = true
+
+	return nil
+}
+
+func TestCloneDoesNotCloseOwnedSinks(t *testing.T) {
+	output := &closingSink{recordSink: recordSink{failing: false, buf: bytes.Buffer{}}, closed: false}
+	mirror := &closingSink{recordSink: recordSink{failing: false, buf: bytes.Buffer{}}, closed: false}
+
+	var errs []error
+
+	client, err := statsd.New(
+		statsd.Output(output),
+		statsd.MirrorTo(mirror, statsd.InfluxDBTags),
+		statsd.ErrorHandler(func(err error) { errs = append(errs, err) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	clone, err := client.Clone(statsd.Writer(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone.Increment("cloned")
+	clone.Close()
+
+	client.Increment("requests")
+	client.Close()
+
+	if len(errs) != 0 {
+		t.Errorf("reported %v", errs)
+	}
+
+	if got, want := buf.String(), "cloned:1|c"; got != want {
+		t.Errorf("clone sent %q, want %q", got, want)
+	}
+
+	if got, want := output.buf.String(), "requests:1|c"; got != want {
+		t.Errorf("client sent %q, want %q", got, want)
+	}
+
+	if got, want := mirror.buf.String(), "requests:1|c"; got != want {
+		t.Errorf("client mirrored %q, want %q", got, want)
+	}
+}
+
+func TestCloneOwnedTransportNeedsDestination(t *testing.T) {
+	client, err := statsd.New(statsd.Output(&recordSink{failing: false, buf: bytes.Buffer{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if _, err := client.Clone(statsd.Prefix("cloned")); !errors.Is(err, statsd.ErrCloneTransport) {
+		t.Errorf("got %v, want ErrCloneTransport", err)
+	}
+}
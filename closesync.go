@@ -0,0 +1,55 @@
+package statsd
+
+import (
+	"fmt"
+	"math"
+	"net"
+)
+
+// flusher is a stream writer buffering data in user space, like bufio.Writer.
+type flusher interface {
+	Flush() error
+}
+
+// syncer is a stream writer that can commit written data, like os.File.
+type syncer interface {
+	Sync() error
+}
+
+// syncConn makes sure the written data leaves the process before the connection is closed.
+func (c *Client) syncConn() error {
+	conn := c.getConn()
+
+	if b, ok := conn.(*batchingConn); ok {
+		if err := b.Flush(); err != nil {
+			return err
+		}
+
+		conn = b.WriteCloser
+	}
+
+	switch conn := conn.(type) {
+	case nopCloser:
+		if f, ok := conn.Writer.(flusher); ok {
+			if err := f.Flush(); err != nil {
+				return fmt.Errorf("statsd: %w", err)
+			}
+		}
+
+		if s, ok := conn.Writer.(syncer); ok {
+			if err := s.Sync(); err != nil {
+				return fmt.Errorf("statsd: %w", err)
+			}
+		}
+	case *net.TCPConn:
+		if c.syncTimeout <= 0 {
+			break // A linger time of zero would discard the unsent data
+		}
+
+		if err := conn.SetLinger(int(math.Ceil(c.syncTimeout.Seconds()))); err != nil {
+			return fmt.Errorf("statsd: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package statsd
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// coalescedLine is a line of a flush, either kept as is or a counter summed over identical lines.
+type coalescedLine struct {
+	raw    []byte
+	name   []byte
+	suffix []byte
+	value  int64
+}
+
+// coalesceCounters merges counter lines that differ only in their value into a single line
+// holding the sum ("x:1|c" × 500 → "x:500|c"). Lines keep the order of their first occurrence.
+func coalesceCounters(data []byte) []byte {
+	var (
+		lines  = make([]coalescedLine, 0, bytes.Count(data, []byte{'\n'})+1)
+		index  = make(map[string]int)
+		merged = false
+	)
+
+	for _, raw := range bytes.Split(data, []byte{'\n'}) {
+		name, value, suffix, ok := splitCounter(raw)
+		if !ok {
+			lines = append(lines, coalescedLine{raw: raw, name: nil, suffix: nil, value: 0})
+
+			continue
+		}
+
+		id := string(name) + "\x00" + string(suffix)
+
+		if i, ok := index[id]; ok {
+			lines[i].value += value
+			merged = true
+
+			continue
+		}
+
+		index[id] = len(lines)
+		lines = append(lines, coalescedLine{raw: nil, name: name, suffix: suffix, value: value})
+	}
+
+	if !merged {
+		return data
+	}
+
+	out := make([]byte, 0, len(data))
+
+	for i, line := range lines {
+		if i > 0 {
+			out = append(out, '\n')
+		}
+
+		if line.raw != nil {
+			out = append(out, line.raw...)
+
+			continue
+		}
+
+		out = append(out, line.name...)
+		out = append(out, ':')
+		out = strconv.AppendInt(out, line.value, 10)
+		out = append(out, line.suffix...)
+	}
+
+	return out
+}
+
+// splitCounter splits an unsampled counter line into its name, value and everything following the value.
+func splitCounter(line []byte) ([]byte, int64, []byte, bool) {
+	colon := bytes.IndexByte(line, ':')
+	if colon < 0 {
+		return nil, 0, nil, false
+	}
+
+	end := bytes.IndexByte(line[colon+1:], '|')
+	if end < 0 {
+		return nil, 0, nil, false
+	}
+
+	end += colon + 1
+	suffix := line[end:]
+
+	if !bytes.Equal(suffix, []byte("|c")) && !bytes.HasPrefix(suffix, []byte("|c|#")) {
+		return nil, 0, nil, false
+	}
+
+	value, err := strconv.ParseInt(string(line[colon+1:end]), 10, 64)
+	if err != nil {
+		return nil, 0, nil, false
+	}
+
+	return line[:colon], value, suffix, true
+}
@@ -0,0 +1,183 @@
+package statsd
+
+import (
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Config is the effective configuration of a client, with defaults applied.
+// It is meant to be logged at startup and compared across deployments.
+type Config struct {
+	// Transport is how the client sends payloads: "udp", "writer", "sink", "file", "activation" or "auto".
+	Transport string
+	// Address is the address of the StatsD server, if the client dials it.
+	Address string
+
+	MaxBufferSize      int
+	FlushInterval      time.Duration
+	FlushPacing        time.Duration
+	SharedScheduler    bool
+	CallerDriven       bool
+	Unbuffered         bool
+	Prefix             string
+	Tags               []Tag
+	SourceHost         string
+	TagFormat          TagFormat
+	DropTags           bool
+	TagsInName         []string
+	Separator          string
+	TrailingSeparator  bool
+	MaxLineLength      int
+	LinePolicy         LinePolicy
+	Sanitization       Sanitization
+	TimingUnit         time.Duration
+	TimingType         TimingType
+	TypePrefixes       TypeNames
+	TypeSuffixes       TypeNames
+	CoalesceCounters   bool
+	InstrumentTTL      int
+	ReservoirSize      int
+	ReconnectOnRefused bool
+	Retries            int
+	RetryBackoff       time.Duration
+	Pooling            bool
+	ErrorChannelSize   int
+	Disabled           bool
+	StallTimeout       time.Duration
+	MaxQueueSize       int
+	DebugSequence      string
+	NamespaceQuota     int
+	ExtendedTypes      bool
+	BatchID            string
+	SampleRates        map[string]float64
+	SyncOnClose        bool
+	SyncTimeout        time.Duration
+	StrictMetrics      []MetricDefinition
+	WriteBatching      time.Duration
+	ErrorCounter       string
+	CallbackBudget     time.Duration
+	RetainFailedChunks int
+	PriorityReserve    int
+	PriorityMetrics    []string
+	DebugLevel         int
+	DebugBudget        int
+	ReservedTags       []string
+	Mirrors            []TagFormat
+	StartCounter       string
+	AggregateGauges    bool
+}
+
+// Config returns the effective configuration of the client.
+func (c *Client) Config() Config {
+	cfg := c.config
+	cfg.Tags = slices.Clone(cfg.Tags)
+	cfg.SampleRates = maps.Clone(cfg.SampleRates)
+	cfg.StrictMetrics = slices.Clone(cfg.StrictMetrics)
+	cfg.Mirrors = slices.Clone(cfg.Mirrors)
+	cfg.Disabled = !c.Enabled()
+
+	return cfg
+}
+
+// Hash returns a short hash of the configuration, e.g. "9f86d081884c7d65", equal for clients configured
+// the same way, so deployments can be compared by a tag. Whether the client is disabled isn't hashed.
+func (cfg Config) Hash() string {
+	cfg.Disabled = false
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%+v", cfg)
+
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// config returns the configuration described by the options.
+func (o *options) config() Config {
+	transport, addr := "udp", ""
+
+	switch {
+	case o.writer != nil:
+		transport = "writer"
+	case o.sink != nil:
+		transport = "sink"
+	case o.file != nil:
+		transport = "file"
+	case o.activation:
+		transport = "activation"
+	case o.autoDetect:
+		transport = "auto"
+	default:
+		addr = address(o)
+	}
+
+	return Config{
+		Transport:          transport,
+		Address:            addr,
+		MaxBufferSize:      o.maxBufferSize,
+		FlushInterval:      o.flushInterval,
+		FlushPacing:        o.pacing,
+		SharedScheduler:    o.scheduler != nil,
+		CallerDriven:       o.callerDriven,
+		Unbuffered:         o.unbuffered,
+		Prefix:             o.prefix,
+		Tags:               slices.Clone(expandTags(o.tags)),
+		SourceHost:         o.sourceHost,
+		TagFormat:          o.tagFormat,
+		DropTags:           o.dropTags,
+		TagsInName:         slices.Clone(o.nameTags),
+		Separator:          o.separator,
+		TrailingSeparator:  o.trailingSeparator,
+		MaxLineLength:      o.maxLineLength,
+		LinePolicy:         o.linePolicy,
+		Sanitization:       o.sanitization,
+		TimingUnit:         o.timingUnit,
+		TimingType:         o.timingType,
+		TypePrefixes:       o.typePrefixes,
+		TypeSuffixes:       o.typeSuffixes,
+		CoalesceCounters:   o.coalesce,
+		InstrumentTTL:      o.instrumentTTL,
+		ReservoirSize:      o.reservoirSize,
+		ReconnectOnRefused: o.reconnect,
+		Retries:            o.retries,
+		RetryBackoff:       o.retryBackoff,
+		Pooling:            o.pooling,
+		ErrorChannelSize:   o.errorChannelSize,
+		Disabled:           o.disabled,
+		StallTimeout:       o.stallTimeout,
+		MaxQueueSize:       o.maxQueueSize,
+		DebugSequence:      o.debugSequence,
+		NamespaceQuota:     o.namespaceQuota,
+		ExtendedTypes:      o.extendedTypes,
+		BatchID:            o.batchKey,
+		SampleRates:        maps.Clone(o.sampleRates),
+		SyncOnClose:        o.syncOnClose,
+		SyncTimeout:        o.syncTimeout,
+		StrictMetrics:      slices.Clone(o.definitions),
+		WriteBatching:      o.writeBatching,
+		ErrorCounter:       o.errorCounter,
+		CallbackBudget:     o.callbackBudget,
+		RetainFailedChunks: o.retainLimit,
+		PriorityReserve:    o.priorityReserve,
+		PriorityMetrics:    slices.Clone(o.priorityPatterns),
+		DebugLevel:         o.debugLevel,
+		DebugBudget:        o.debugBudget,
+		ReservedTags:       slices.Clone(o.reservedTags),
+		Mirrors:            mirrorFormats(o.mirrors),
+		StartCounter:       o.startCounter,
+		AggregateGauges:    o.aggregateGauges && !o.unbuffered,
+	}
+}
+
+// mirrorFormats returns the tag formats of the mirrors.
+func mirrorFormats(mirrors []*mirror) []TagFormat {
+	var formats []TagFormat
+
+	for _, m := range mirrors {
+		formats = append(formats, m.format)
+	}
+
+	return formats
+}
@@ -0,0 +1,254 @@
+// Package conformance provides table-driven conformance cases for the StatsD dialects supported by
+// the statsd package: DogStatsD, Telegraf and Graphite. Run them against custom serializers and
+// sinks to verify they write the lines these servers expect:
+//
+//	func TestEncoder(t *testing.T) {
+//		for _, d := range conformance.Dialects() {
+//			t.Run(d.Name, func(t *testing.T) {
+//				conformance.RunEncoder(t, d, myEncoder(d.Format))
+//			})
+//		}
+//	}
+package conformance
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devem-tech/statsd"
+)
+
+// Dialect is a StatsD dialect, i.e. the line syntax a server accepts.
+type Dialect struct {
+	// Name is the name of the dialect, e.g. "DogStatsD".
+	Name string
+	// Format is the tag format of the dialect.
+	Format statsd.TagFormat
+	// Option configures a client for the dialect.
+	Option statsd.Option
+}
+
+// Case is a metric and the line a dialect expects for it.
+type Case struct {
+	// Name describes the case, e.g. "sampled counter".
+	Name string
+	// Line is the metric in its parsed form, as given to encoders.
+	Line statsd.Line
+	// Options configure the client sending the metric in addition to the option of the dialect.
+	Options []statsd.Option
+	// Send sends the metric with a client, for sinks.
+	Send func(c *statsd.Client)
+	// Want is the expected line, without a line terminator.
+	Want string
+}
+
+// DogStatsD returns the dialect of the DataDog agent.
+func DogStatsD() Dialect {
+	return Dialect{Name: "DogStatsD", Format: statsd.DogStatsDTags, Option: statsd.DataDog()}
+}
+
+// Telegraf returns the dialect of the Telegraf StatsD input.
+func Telegraf() Dialect {
+	return Dialect{Name: "Telegraf", Format: statsd.InfluxDBTags, Option: statsd.Telegraf()}
+}
+
+// Graphite returns the dialect of the StatsD daemon from Etsy with a Graphite backend.
+func Graphite() Dialect {
+	return Dialect{Name: "Graphite", Format: statsd.GraphiteTags, Option: statsd.GraphiteStatsd()}
+}
+
+// Dialects returns all supported dialects.
+func Dialects() []Dialect {
+	return []Dialect{DogStatsD(), Telegraf(), Graphite()}
+}
+
+// Cases returns the conformance cases of the dialect.
+func Cases(d Dialect) []Case {
+	var cases []Case
+
+	for _, c := range table() {
+		want, ok := c.want[d.Format]
+		if !ok {
+			continue
+		}
+
+		cases = append(cases, Case{Name: c.name, Line: c.line, Options: c.options, Send: c.send, Want: want})
+	}
+
+	return cases
+}
+
+// RunEncoder runs the cases of the dialect against an encoder of single lines as subtests.
+func RunEncoder(t *testing.T, d Dialect, encode func(statsd.Line) []byte) {
+	t.Helper()
+
+	for _, c := range Cases(d) {
+		t.Run(c.Name, func(t *testing.T) {
+			if got := string(encode(c.Line)); got != c.Want {
+				t.Errorf("encoded %q, want %q", got, c.Want)
+			}
+		})
+	}
+}
+
+// RunSink runs the cases of the dialect as subtests, sending every metric with a client writing to the sink.
+// The received function returns the data the sink delivered since it was last called, e.g. read by a test
+// server. The sink isn't closed.
+func RunSink(t *testing.T, d Dialect, sink statsd.Sink, received func() []byte) {
+	t.Helper()
+
+	for _, c := range Cases(d) {
+		t.Run(c.Name, func(t *testing.T) {
+			opts := []statsd.Option{d.Option, statsd.Output(openSink{sink}), statsd.RandSource(keepSource{})}
+
+			client, err := statsd.New(append(opts, c.Options...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c.Send(client)
+			client.Close()
+
+			if got := strings.TrimRight(string(received()), "\n"); got != c.Want {
+				t.Errorf("sink delivered %q, want %q", got, c.Want)
+			}
+		})
+	}
+}
+
+// openSink keeps the sink open when the client is closed.
+type openSink struct {
+	statsd.Sink
+}
+
+// Close does nothing.
+func (openSink) Close() error {
+	return nil
+}
+
+// keepSource makes clients keep every sampled metric.
+type keepSource struct{}
+
+// Uint64 returns 0, below every sample rate.
+func (keepSource) Uint64() uint64 {
+	return 0
+}
+
+// tableCase is a conformance case with the lines expected by the dialects supporting it.
+type tableCase struct {
+	name    string
+	line    statsd.Line
+	options []statsd.Option
+	send    func(c *statsd.Client)
+	want    map[statsd.TagFormat]string
+}
+
+// table returns the conformance cases of all dialects.
+func table() []tableCase {
+	tags := []statsd.Tag{{Key: "region", Value: "eu"}, {Key: "status", Value: "ok"}}
+
+	return []tableCase{
+		{
+			name:    "counter",
+			line:    line("requests", "1", "c", 1, nil, 0),
+			options: nil,
+			send:    func(c *statsd.Client) { c.Increment("requests") },
+			want: map[statsd.TagFormat]string{
+				statsd.DogStatsDTags: "requests:1|c",
+				statsd.InfluxDBTags:  "requests:1|c",
+				statsd.GraphiteTags:  "requests:1|c",
+			},
+		},
+		{
+			name:    "tagged counter",
+			line:    line("http.requests", "3", "c", 1, tags, 0),
+			options: nil,
+			send:    func(c *statsd.Client) { c.Count("http.requests", 3, tags...) },
+			want: map[statsd.TagFormat]string{
+				statsd.DogStatsDTags: "http.requests:3|c|#region:eu,status:ok",
+				statsd.InfluxDBTags:  "http.requests,region=eu,status=ok:3|c",
+				statsd.GraphiteTags:  "http.requests;region=eu;status=ok:3|c",
+			},
+		},
+		{
+			name:    "sampled counter",
+			line:    line("cache.hits", "1", "c", 0.25, tags[:1], 0),
+			options: nil,
+			send:    func(c *statsd.Client) { c.Sample(0.25).Increment("cache.hits", tags[:1]...) },
+			want: map[statsd.TagFormat]string{
+				statsd.DogStatsDTags: "cache.hits:1|c|@0.25|#region:eu",
+				statsd.InfluxDBTags:  "cache.hits,region=eu:1|c|@0.25",
+				statsd.GraphiteTags:  "cache.hits;region=eu:1|c|@0.25",
+			},
+		},
+		{
+			name:    "gauge",
+			line:    line("queue.depth", "3.5", "g", 1, tags[:1], 0),
+			options: nil,
+			send:    func(c *statsd.Client) { c.Gauge("queue.depth", 3.5, tags[:1]...) },
+			want: map[statsd.TagFormat]string{
+				statsd.DogStatsDTags: "queue.depth:3.5|g|#region:eu",
+				statsd.InfluxDBTags:  "queue.depth,region=eu:3.5|g",
+				statsd.GraphiteTags:  "queue.depth;region=eu:3.5|g",
+			},
+		},
+		{
+			name:    "timer",
+			line:    line("db.query", "250", "ms", 1, nil, 0),
+			options: nil,
+			send:    func(c *statsd.Client) { c.Timing("db.query", 250*time.Millisecond) },
+			want: map[statsd.TagFormat]string{
+				statsd.DogStatsDTags: "db.query:250|ms",
+				statsd.InfluxDBTags:  "db.query:250|ms",
+				statsd.GraphiteTags:  "db.query:250|ms",
+			},
+		},
+		{
+			name:    "set",
+			line:    line("users", "user42", "s", 1, nil, 0),
+			options: nil,
+			send:    func(c *statsd.Client) { c.Set("users", "user42") },
+			want: map[statsd.TagFormat]string{
+				statsd.DogStatsDTags: "users:user42|s",
+				statsd.InfluxDBTags:  "users:user42|s",
+				statsd.GraphiteTags:  "users:user42|s",
+			},
+		},
+		{
+			name:    "histogram",
+			line:    line("payload.size", "1.5", "h", 1, nil, 0),
+			options: []statsd.Option{statsd.TimingsAs(statsd.HistogramType)},
+			send:    func(c *statsd.Client) { c.Timing("payload.size", 1500*time.Microsecond) },
+			want: map[statsd.TagFormat]string{
+				statsd.DogStatsDTags: "payload.size:1.5|h",
+				statsd.InfluxDBTags:  "payload.size:1.5|h",
+			},
+		},
+		{
+			name:    "distribution",
+			line:    line("latency", "12", "d", 1, tags[:1], 0),
+			options: []statsd.Option{statsd.TimingsAs(statsd.DistributionType)},
+			send:    func(c *statsd.Client) { c.Timing("latency", 12*time.Millisecond, tags[:1]...) },
+			want: map[statsd.TagFormat]string{
+				statsd.DogStatsDTags: "latency:12|d|#region:eu",
+			},
+		},
+		{
+			name:    "timestamped gauge",
+			line:    line("temperature", "21.5", "g", 1, nil, 1_700_000_000), //nolint:mnd
+			options: nil,
+			send: func(c *statsd.Client) {
+				c.WithTimestamp(time.Unix(1_700_000_000, 0)).Gauge("temperature", 21.5) //nolint:mnd
+			},
+			want: map[statsd.TagFormat]string{
+				statsd.DogStatsDTags: "temperature:21.5|g|T1700000000",
+			},
+		},
+	}
+}
+
+// line returns a parsed line.
+func line(name, value, mt string, rate float64, tags []statsd.Tag, timestamp int64) statsd.Line {
+	return statsd.Line{Name: name, Value: value, Type: mt, Rate: rate, Tags: tags, Timestamp: timestamp}
+}
@@ -0,0 +1,57 @@
+package statsd
+
+import "time"
+
+// Standard names of the metrics of message consumers, tagged with the topic.
+const (
+	ConsumerProcessed     = "consumer.processed"
+	ConsumerErrors        = "consumer.errors"
+	ConsumerDuration      = "consumer.duration"
+	ConsumerLag           = "consumer.lag"
+	ConsumerLastProcessed = "consumer.last_processed"
+
+	TagTopic = "topic"
+)
+
+// InstrumentConsumer wraps a message handler, e.g. of a Kafka or NATS consumer, to report
+// processed messages, errors and processing durations, tagged with the topic, and the Unix time
+// of the last processed message as a gauge. If timestamp isn't nil, it returns the time a message
+// was produced at, and the delay until it was processed is sent as the consumer lag. A panicking
+// handler counts as an error and the panic is propagated.
+//
+//	handle := statsd.InstrumentConsumer(client, "orders", handleOrder, func(m kafka.Message) time.Time {
+//		return m.Time
+//	})
+func InstrumentConsumer[M any](
+	c *Client, topic string, handler func(M) error, timestamp func(M) time.Time,
+) func(M) error {
+	tags := []Tag{{Key: TagTopic, Value: topic}}
+
+	return func(msg M) error {
+		start := time.Now()
+
+		if timestamp != nil {
+			if produced := timestamp(msg); !produced.IsZero() {
+				c.Timing(ConsumerLag, start.Sub(produced), tags...)
+			}
+		}
+
+		failed := true
+
+		defer func() {
+			c.Timing(ConsumerDuration, time.Since(start), tags...)
+			c.Increment(ConsumerProcessed, tags...)
+
+			if failed {
+				c.Increment(ConsumerErrors, tags...)
+			}
+
+			c.Gauge(ConsumerLastProcessed, float64(time.Now().Unix()), tags...)
+		}()
+
+		err := handler(msg)
+		failed = err != nil
+
+		return err
+	}
+}
@@ -0,0 +1,40 @@
+package statsd_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/devem-tech/statsd"
+)
+
+func TestInstrumentConsumerPanic(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := statsd.New(statsd.Writer(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handle := statsd.InstrumentConsumer(client, "orders", func(string) error {
+		panic("malformed order")
+	}, nil)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("the panic wasn't propagated")
+			}
+		}()
+
+		_ = handle("order")
+	}()
+
+	client.Close()
+
+	for _, want := range []string{"consumer.duration;", "consumer.processed;topic=orders:1|c", "consumer.errors;topic=orders:1|c"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("sent %q, want %s", buf.String(), want)
+		}
+	}
+}
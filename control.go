@@ -0,0 +1,112 @@
+package statsd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"time"
+)
+
+// ControlFile is the content of a control file read by WatchControlFile, in JSON:
+//
+//	{"sample_rates": {"cache.*": 0.01}, "drop": ["debug.*"], "debug_level": 0}
+//
+// Settings left out keep their configured values.
+type ControlFile struct {
+	// SampleRates override the rates of the SampleRates option with the same patterns and add to them.
+	SampleRates map[string]float64 `json:"sample_rates"`
+	// Drop are glob patterns of metric names to drop, see DropMetrics.
+	Drop []string `json:"drop"`
+	// DebugLevel overrides the level of debug metrics, see SetDebugLevel.
+	DebugLevel *int `json:"debug_level"`
+}
+
+// WatchControlFile reads the control file at the path every interval, until ctx is done, and applies
+// the overrides it holds, so operators can throttle the metrics of a service without a deploy. Changes
+// are detected by the modification time and size of the file; removing the file restores the configured
+// settings. Files that can't be read or parsed are reported to the error handler and the previous
+// overrides stay in effect. Run it in its own goroutine:
+//
+//	go client.WatchControlFile(ctx, "/etc/statsd/control.json", 10*time.Second)
+//
+// It returns the context's error.
+func (c *Client) WatchControlFile(ctx context.Context, path string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last fs.FileInfo
+
+	for {
+		last = c.readControlFile(path, last)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("statsd: %w", ctx.Err())
+		}
+	}
+}
+
+// readControlFile applies the control file if it changed since it was last seen as last,
+// returning the information of the file as seen now.
+func (c *Client) readControlFile(path string, last fs.FileInfo) fs.FileInfo {
+	info, err := os.Stat(path)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if last != nil {
+			c.applyControl(ControlFile{SampleRates: nil, Drop: nil, DebugLevel: nil})
+		}
+
+		return nil
+	case err != nil:
+		c.reportError(fmt.Errorf("statsd: %w", err))
+
+		return last
+	case last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size():
+		return last
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.reportError(fmt.Errorf("statsd: %w", err))
+
+		return last
+	}
+
+	var control ControlFile
+	if err := json.Unmarshal(data, &control); err != nil {
+		c.reportError(fmt.Errorf("statsd: control file %s: %w", path, err))
+
+		return info // Don't report the same broken file again
+	}
+
+	c.applyControl(control)
+
+	return info
+}
+
+// applyControl applies the overrides of a control file, restoring the configured settings left out.
+func (c *Client) applyControl(control ControlFile) {
+	var rates map[string]float64
+
+	if control.SampleRates != nil {
+		rates = make(map[string]float64, len(c.config.SampleRates)+len(control.SampleRates))
+		maps.Copy(rates, c.config.SampleRates)
+		maps.Copy(rates, control.SampleRates)
+	}
+
+	c.SetSampleRates(rates)
+	c.DropMetrics(control.Drop...)
+
+	level := c.config.DebugLevel
+	if control.DebugLevel != nil {
+		level = *control.DebugLevel
+	}
+
+	c.SetDebugLevel(level)
+}
@@ -0,0 +1,108 @@
+package statsd
+
+import "time"
+
+// Standard metric names following the RED method (rate, errors, duration) for services and the
+// USE method (utilization, saturation, errors) for resources, so teams converge on identical names.
+const (
+	HTTPRequests = "http.server.requests"
+	HTTPErrors   = "http.server.errors"
+	HTTPDuration = "http.server.duration"
+
+	GRPCRequests = "grpc.server.requests"
+	GRPCErrors   = "grpc.server.errors"
+	GRPCDuration = "grpc.server.duration"
+
+	DBQueries  = "db.client.queries"
+	DBErrors   = "db.client.errors"
+	DBDuration = "db.client.duration"
+
+	ResourceUtilization = "resource.utilization"
+	ResourceSaturation  = "resource.saturation"
+	ResourceErrors      = "resource.errors"
+)
+
+// Standard tag keys of the metrics above.
+const (
+	TagMethod    = "method"
+	TagRoute     = "route"
+	TagStatus    = "status"
+	TagService   = "service"
+	TagCode      = "code"
+	TagSystem    = "system"
+	TagOperation = "operation"
+	TagResource  = "resource"
+)
+
+// StatusClass returns the class of an HTTP status code, e.g. "2xx", keeping tag cardinality low.
+func StatusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+
+	return statusClasses[status/100]
+}
+
+// statusClasses holds the classes of HTTP status codes, so StatusClass doesn't allocate.
+var statusClasses = [...]string{1: "1xx", 2: "2xx", 3: "3xx", 4: "4xx", 5: "5xx"} //nolint:gochecknoglobals
+
+// RecordHTTP records an HTTP request with the standard names and tags: a request counter, an error
+// counter for 5xx responses and the duration. The route should be the route pattern, not the path.
+func (c *Client) RecordHTTP(method, route string, status int, duration time.Duration) {
+	tags := []Tag{
+		{Key: TagMethod, Value: method},
+		{Key: TagRoute, Value: route},
+		{Key: TagStatus, Value: StatusClass(status)},
+	}
+
+	c.Increment(HTTPRequests, tags...)
+
+	if status >= 500 {
+		c.Increment(HTTPErrors, tags...)
+	}
+
+	c.Timing(HTTPDuration, duration, tags...)
+}
+
+// RecordGRPC records a gRPC call with the standard names and tags: a request counter, an error
+// counter for codes other than "OK" and the duration.
+func (c *Client) RecordGRPC(service, method, code string, duration time.Duration) {
+	tags := []Tag{
+		{Key: TagService, Value: service},
+		{Key: TagMethod, Value: method},
+		{Key: TagCode, Value: code},
+	}
+
+	c.Increment(GRPCRequests, tags...)
+
+	if code != "OK" {
+		c.Increment(GRPCErrors, tags...)
+	}
+
+	c.Timing(GRPCDuration, duration, tags...)
+}
+
+// RecordDB records a database query with the standard names and tags: a query counter, an error
+// counter if err isn't nil and the duration. The system is e.g. "postgresql" and the operation
+// e.g. "select".
+func (c *Client) RecordDB(system, operation string, err error, duration time.Duration) {
+	tags := []Tag{{Key: TagSystem, Value: system}, {Key: TagOperation, Value: operation}}
+
+	c.Increment(DBQueries, tags...)
+
+	if err != nil {
+		c.Increment(DBErrors, tags...)
+	}
+
+	c.Timing(DBDuration, duration, tags...)
+}
+
+// RecordResource records the state of a resource, e.g. a connection pool, with the standard names
+// of the USE method: its utilization and saturation as gauges, and its errors as a counter.
+func (c *Client) RecordResource(resource string, utilization, saturation float64, errors int64) {
+	tags := []Tag{{Key: TagResource, Value: resource}}
+
+	c.Gauge(ResourceUtilization, utilization, tags...)
+	c.Gauge(ResourceSaturation, saturation, tags...)
+	c.Count(ResourceErrors, errors, tags...)
+}
@@ -0,0 +1,211 @@
+package statsd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DataDog metric intake types of the series API.
+const (
+	dataDogCount = 1
+	dataDogGauge = 3
+)
+
+// DataDogSink is a sink submitting metrics to the DataDog APIs directly, for environments without
+// a local agent. It converts the DogStatsD lines of a payload into a series payload and a distribution
+// points payload, so the client must use DogStatsD tags, e.g. with the DataDog preset. Counters are sent
+// as counts, corrected by their sample rates, timers, histograms and distributions as distributions,
+// uncorrected as the API takes no sample rates, and all other numeric metrics as gauges; sets aren't
+// supported by the APIs and are skipped. Lines with Unix timestamps keep them. It is safe for concurrent use.
+type DataDogSink struct {
+	series        *HTTPSink
+	distributions *HTTPSink
+}
+
+var _ Sink = (*DataDogSink)(nil)
+
+// dataDogSeries is the request body of the series API.
+type dataDogSeries struct {
+	Series []dataDogMetric `json:"series"`
+}
+
+// dataDogMetric is a single point of a metric in the series API.
+type dataDogMetric struct {
+	Metric string         `json:"metric"`
+	Type   int            `json:"type"`
+	Points []dataDogPoint `json:"points"`
+	Tags   []string       `json:"tags,omitempty"`
+}
+
+// dataDogPoint is a timestamped value in the series API.
+type dataDogPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// dataDogDistributions is the request body of the distribution points API.
+type dataDogDistributions struct {
+	Series []dataDogDistribution `json:"series"`
+	lines  []string              // Lines of the distributions, to write them again
+}
+
+// dataDogDistribution is a single point of a distribution in the distribution points API.
+type dataDogDistribution struct {
+	Metric string                     `json:"metric"`
+	Points []dataDogDistributionPoint `json:"points"`
+	Tags   []string                   `json:"tags,omitempty"`
+}
+
+// dataDogDistributionPoint is a timestamp and its values, encoded as [timestamp, [values]].
+type dataDogDistributionPoint struct {
+	Timestamp int64
+	Values    []float64
+}
+
+// MarshalJSON encodes the point as the API expects.
+func (p dataDogDistributionPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{p.Timestamp, p.Values}) //nolint:wrapcheck
+}
+
+// NewDataDogSink returns a sink submitting metrics to the series and distribution points APIs of the DataDog
+// site, e.g. "datadoghq.com" or "datadoghq.eu", authenticated with the API key. The options configure the
+// underlying HTTPSinks.
+func NewDataDogSink(site, apiKey string, opts ...HTTPSinkOption) *DataDogSink {
+	opts = append([]HTTPSinkOption{
+		HTTPHeader("Content-Type", "application/json"),
+		HTTPHeader("DD-API-KEY", apiKey),
+	}, opts...)
+
+	return &DataDogSink{
+		series:        NewHTTPSink("https://api."+site+"/api/v2/series", opts...),
+		distributions: NewHTTPSink("https://api."+site+"/api/v1/distribution_points", opts...),
+	}
+}
+
+// Write converts the lines of the payload and submits them as a series payload and a distribution
+// points payload, skipping empty ones. If only the distribution points fail to be submitted, it returns
+// a *PartialWriteError holding their lines, so the series aren't submitted twice.
+func (s *DataDogSink) Write(p []byte) (int, error) {
+	series, distributions, err := dataDogPayloadsOf(string(p), time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+
+	if len(series.Series) > 0 {
+		if err := postJSON(s.series, series); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(distributions.Series) > 0 {
+		if err := postJSON(s.distributions, distributions); err != nil {
+			if len(series.Series) > 0 {
+				return 0, &PartialWriteError{Err: err, Unwritten: []byte(strings.Join(distributions.lines, "\n"))}
+			}
+
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Close releases the idle connections of the sink.
+func (s *DataDogSink) Close() error {
+	return errors.Join(s.series.Close(), s.distributions.Close())
+}
+
+// postJSON posts the value encoded as JSON to the sink.
+func postJSON(sink *HTTPSink, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("statsd: %w", err)
+	}
+
+	_, err = sink.Write(body)
+
+	return err
+}
+
+// dataDogPayloadsOf converts the lines of a payload into a series payload and a distribution points payload.
+func dataDogPayloadsOf(payload string, now int64) (dataDogSeries, dataDogDistributions, error) {
+	series := dataDogSeries{Series: nil}
+	distributions := dataDogDistributions{Series: nil, lines: nil}
+
+	for _, line := range strings.Split(payload, "\n") {
+		if line == "" {
+			continue
+		}
+
+		l, err := ParseLine(line, DogStatsDTags)
+		if err != nil {
+			return series, distributions, err
+		}
+
+		metric, ok, err := dataDogMetricOf(l, now)
+		if err != nil {
+			return series, distributions, err
+		}
+
+		switch {
+		case !ok:
+		case l.Type == "ms" || l.Type == "h" || l.Type == "d":
+			distributions.Series = append(distributions.Series, metric.distribution())
+			distributions.lines = append(distributions.lines, line)
+		default:
+			series.Series = append(series.Series, metric)
+		}
+	}
+
+	return series, distributions, nil
+}
+
+// distribution returns the point of the metric as a distribution.
+func (m dataDogMetric) distribution() dataDogDistribution {
+	point := dataDogDistributionPoint{Timestamp: m.Points[0].Timestamp, Values: []float64{m.Points[0].Value}}
+
+	return dataDogDistribution{Metric: m.Metric, Points: []dataDogDistributionPoint{point}, Tags: m.Tags}
+}
+
+// dataDogMetricOf converts a parsed line, reporting false for lines the series API doesn't support.
+func dataDogMetricOf(l Line, now int64) (dataDogMetric, bool, error) {
+	metric := dataDogMetric{Metric: l.Name, Type: dataDogGauge, Points: nil, Tags: nil}
+
+	if l.Type == "s" {
+		return metric, false, nil
+	}
+
+	value, err := strconv.ParseFloat(l.Value, 64)
+	if err != nil {
+		return metric, false, fmt.Errorf("%w: value %q of %s", ErrMalformedLine, l.Value, l.Name)
+	}
+
+	if l.Type == "c" {
+		metric.Type = dataDogCount
+
+		if l.Rate > 0 {
+			value /= l.Rate
+		}
+	}
+
+	timestamp := l.Timestamp
+	if timestamp == 0 {
+		timestamp = now
+	}
+
+	metric.Points = []dataDogPoint{{Timestamp: timestamp, Value: value}}
+
+	for _, tag := range l.Tags {
+		if tag.Value == "" {
+			metric.Tags = append(metric.Tags, tag.Key)
+		} else {
+			metric.Tags = append(metric.Tags, tag.Key+":"+tag.Value)
+		}
+	}
+
+	return metric, true, nil
+}
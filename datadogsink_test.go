@@ -0,0 +1,171 @@
+package statsd_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devem-tech/statsd"
+)
+
+// recordTransport records the bodies of requests by URL path and accepts them.
+type recordTransport struct {
+	lock   sync.Mutex
+	bodies map[string]string
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	t.lock.Lock()
+	t.bodies[req.URL.Path] = string(body)
+	t.lock.Unlock()
+
+	return &http.Response{
+		Status:     "202 Accepted",
+		StatusCode: http.StatusAccepted,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Header:     http.Header{},
+		Request:    req,
+	}, nil
+}
+
+func TestDataDogSinkSendsTimingsAsDistributions(t *testing.T) {
+	transport := &recordTransport{lock: sync.Mutex{}, bodies: make(map[string]string)}
+	httpClient := &http.Client{Transport: transport, CheckRedirect: nil, Jar: nil, Timeout: time.Second}
+	sink := statsd.NewDataDogSink("datadoghq.com", "key", statsd.HTTPClient(httpClient))
+
+	if _, err := sink.Write([]byte("requests:2|c|@0.5|#route:home\nlatency:12|ms|#route:home|T1700000000")); err != nil {
+		t.Fatal(err)
+	}
+
+	var series struct {
+		Series []struct {
+			Metric string `json:"metric"`
+			Type   int    `json:"type"`
+		} `json:"series"`
+	}
+
+	if err := json.Unmarshal([]byte(transport.bodies["/api/v2/series"]), &series); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(series.Series) != 1 || series.Series[0].Metric != "requests" || series.Series[0].Type != 1 {
+		t.Errorf("posted series %+v", series)
+	}
+
+	want := `{"series":[{"metric":"latency","points":[[1700000000,[12]]],"tags":["route:home"]}]}`
+	if got := transport.bodies["/api/v1/distribution_points"]; got != want {
+		t.Errorf("posted distributions %s, want %s", got, want)
+	}
+}
+
+// errTimeout is a transient network error.
+type errTimeout struct{}
+
+func (errTimeout) Error() string   { return "timeout" }
+func (errTimeout) Timeout() bool   { return true }
+func (errTimeout) Temporary() bool { return true }
+
+// failingTransport is a recordTransport counting requests by URL path and failing the first ones
+// to a path with a timeout.
+type failingTransport struct {
+	recordTransport
+
+	lock     sync.Mutex
+	path     string
+	failures int
+	posts    map[string]int
+}
+
+func (t *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.lock.Lock()
+	t.posts[req.URL.Path]++
+	fail := req.URL.Path == t.path && t.failures > 0
+	if fail {
+		t.failures--
+	}
+	t.lock.Unlock()
+
+	if fail {
+		return nil, errTimeout{}
+	}
+
+	return t.recordTransport.RoundTrip(req)
+}
+
+func TestDataDogSinkPartialFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		opt      statsd.Option
+		reported int // Write errors reported
+	}{
+		{name: "retry", opt: statsd.Retry(1, 0), reported: 0},
+		{name: "retain", opt: statsd.RetainFailedChunks(1 << 10), reported: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			transport := &failingTransport{
+				recordTransport: recordTransport{lock: sync.Mutex{}, bodies: make(map[string]string)},
+				lock:            sync.Mutex{},
+				path:            "/api/v1/distribution_points",
+				failures:        1,
+				posts:           make(map[string]int),
+			}
+			httpClient := &http.Client{Transport: transport, CheckRedirect: nil, Jar: nil, Timeout: time.Second}
+
+			var errs []error
+
+			client, err := statsd.New(
+				statsd.Output(statsd.NewDataDogSink("datadoghq.com", "key", statsd.HTTPClient(httpClient))),
+				statsd.CallerDriven(),
+				statsd.ErrorHandler(func(err error) { errs = append(errs, err) }),
+				c.opt,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			now := time.Now()
+			client.Tick(now)
+
+			client.Increment("requests")
+			client.Timing("latency", 12*time.Millisecond)
+
+			// The second flush writes the lines retained by the first one
+			for range 2 {
+				now = now.Add(time.Hour)
+				client.Tick(now)
+			}
+
+			client.Close()
+
+			// The series went through with the first attempt
+			if got := transport.posts["/api/v2/series"]; got != 1 {
+				t.Errorf("posted series %d times, want once", got)
+			}
+
+			if got := transport.posts["/api/v1/distribution_points"]; got != 2 {
+				t.Errorf("posted distribution points %d times, want twice", got)
+			}
+
+			if len(errs) != c.reported {
+				t.Fatalf("reported %v", errs)
+			}
+
+			var werr *statsd.WriteError
+			if c.reported > 0 && (!errors.As(errs[0], &werr) || werr.Lines != 1) {
+				t.Errorf("reported %v, want a write error of the distribution only", errs[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package statsd
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// debugMetrics holds the verbosity level of the debug metrics of a client and their budget.
+type debugMetrics struct {
+	level  atomic.Int32
+	budget int64
+	sent   atomic.Int64 // Debug metrics queued since the last flush
+}
+
+// admit reports whether a debug metric of the level may be queued, taking it from the budget.
+func (d *debugMetrics) admit(level int) bool {
+	if level <= 0 || int32(level) > d.level.Load() { //nolint:gosec
+		return false
+	}
+
+	if d.budget <= 0 {
+		return true
+	}
+
+	if d.sent.Add(1) <= d.budget {
+		return true
+	}
+
+	d.sent.Add(-1)
+
+	return false
+}
+
+// reset restores the budget when the queue was flushed.
+func (d *debugMetrics) reset() {
+	d.sent.Store(0)
+}
+
+// SetDebugLevel sets the verbosity level of debug metrics at runtime: debug metrics of levels up to
+// the level are sent, others are discarded at the cost of an atomic load. Level 0, the default,
+// disables debug metrics. See the DebugMetrics option to bound their volume.
+func (c *Client) SetDebugLevel(level int) {
+	c.debug.level.Store(int32(min(max(level, 0), 1<<30))) //nolint:gosec,mnd
+}
+
+// DebugLevel returns the verbosity level of debug metrics.
+func (c *Client) DebugLevel() int {
+	return int(c.debug.level.Load())
+}
+
+// Debug is a handle sending debug metrics of a verbosity level, only if the level is enabled with
+// SetDebugLevel and the debug budget isn't exhausted, so code can be instrumented heavily but only
+// pays for it while debugging.
+type Debug struct {
+	client *Client
+	level  int
+}
+
+// Debug returns the handle sending debug metrics of the verbosity level, 1 being the least verbose.
+func (c *Client) Debug(level int) Debug {
+	return Debug{client: c, level: level}
+}
+
+// Enabled reports whether debug metrics of the level are sent, so expensive measurements can be skipped.
+func (d Debug) Enabled() bool {
+	return d.level > 0 && int32(d.level) <= d.client.debug.level.Load() //nolint:gosec
+}
+
+// Count sends a counter if the level is enabled.
+func (d Debug) Count(key string, value int64, tags ...Tag) {
+	if value != 0 && d.client.debug.admit(d.level) {
+		d.client.send(key, "c", intValue(value), tags)
+	}
+}
+
+// Increment increases a counter by 1 if the level is enabled.
+func (d Debug) Increment(key string, tags ...Tag) {
+	d.Count(key, 1, tags...)
+}
+
+// Gauge sends a gauge if the level is enabled.
+func (d Debug) Gauge(key string, value float64, tags ...Tag) {
+	if d.client.debug.admit(d.level) {
+		d.client.send(key, "g", floatValue(value), tags)
+	}
+}
+
+// Timing sends a timer if the level is enabled.
+func (d Debug) Timing(key string, duration time.Duration, tags ...Tag) {
+	if d.client.debug.admit(d.level) {
+		d.client.send(key, "ms", durationValue(duration), tags)
+	}
+}
+
+// Timer starts timing and sends the metric via defer if the level is enabled when it is started.
+func (d Debug) Timer(key string, tags ...Tag) func() {
+	if !d.Enabled() {
+		return func() {}
+	}
+
+	start := time.Now()
+
+	return func() {
+		d.Timing(key, time.Since(start), tags...)
+	}
+}
+
+// DebugCount sends a debug counter of verbosity level 1. See Debug.
+func (c *Client) DebugCount(key string, value int64, tags ...Tag) {
+	c.Debug(1).Count(key, value, tags...)
+}
+
+// DebugIncrement increases a debug counter of verbosity level 1 by 1. See Debug.
+func (c *Client) DebugIncrement(key string, tags ...Tag) {
+	c.Debug(1).Count(key, 1, tags...)
+}
+
+// DebugGauge sends a debug gauge of verbosity level 1. See Debug.
+func (c *Client) DebugGauge(key string, value float64, tags ...Tag) {
+	c.Debug(1).Gauge(key, value, tags...)
+}
+
+// DebugTiming sends a debug timer of verbosity level 1. See Debug.
+func (c *Client) DebugTiming(key string, duration time.Duration, tags ...Tag) {
+	c.Debug(1).Timing(key, duration, tags...)
+}
@@ -0,0 +1,73 @@
+package statsd
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MetricDefinition declares a metric the client may send in strict mode.
+type MetricDefinition struct {
+	// Key is the metric name, without the client's prefix but including the namespace, if any.
+	Key string
+	// Type is the StatsD metric type: "c" for counters, "g" for gauges or "ms" for timings.
+	Type string
+	// Tags are the allowed tag keys, besides the client's default tags. Nil allows any tags.
+	Tags []string
+	// Description and Unit document the metric in the schema returned by ExportSchema.
+	Description string
+	Unit        string
+}
+
+// definitions holds the metrics defined for strict mode by name.
+type definitions map[string]MetricDefinition
+
+// newDefinitions returns the definitions by name, or nil if there are none.
+func newDefinitions(defs []MetricDefinition) definitions {
+	if len(defs) == 0 {
+		return nil
+	}
+
+	d := make(definitions, len(defs))
+	for _, def := range defs {
+		d[def.Key] = def
+	}
+
+	return d
+}
+
+// check returns an error if the metric of the namespace, if any, doesn't match its definition.
+func (d definitions) check(ns *Namespace, key, mt string, tags []Tag) error {
+	name := key
+	if ns != nil {
+		name = ns.prefix + key
+	}
+
+	def, ok := d[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUndefinedMetric, name)
+	}
+
+	if def.Type != mt {
+		return fmt.Errorf("%w: %q is defined as %q, sent as %q", ErrMetricType, name, def.Type, mt)
+	}
+
+	if def.Tags == nil {
+		return nil
+	}
+
+	for tag := range allTags(tags) {
+		if !slices.Contains(def.Tags, tag.Key) {
+			return fmt.Errorf("%w: %q on %q", ErrTagNotAllowed, tag.Key, name)
+		}
+	}
+
+	if ns != nil {
+		for tag := range allTags(ns.tags) {
+			if !slices.Contains(def.Tags, tag.Key) {
+				return fmt.Errorf("%w: %q on %q", ErrTagNotAllowed, tag.Key, name)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package statsd
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Delegate returns a namespace for a library to emit its metrics through the host's client, owned by
+// the library: names are prefixed with the owner, e.g. "kafka-lib.consumer.lag", and so are the keys
+// of all its tags, e.g. "kafka-lib.topic", so the library can neither collide with the host's metrics
+// nor re-label them. The owner is sanitized like a segment of Name. Nested namespaces are sandboxed too.
+func (c *Client) Delegate(owner string, tags ...Tag) *Namespace {
+	owner = sanitizeSegment(owner)
+
+	ns := c.newNamespace("", nil, owner, nil)
+	ns.tagPrefix = owner + "."
+	ns.tags = ns.appendSandboxed(ns.tags, tags)
+
+	return ns
+}
+
+// sandboxedKeys caches the tag keys of a delegated namespace with its prefix.
+type sandboxedKeys struct {
+	keys sync.Map
+	size atomic.Int64
+}
+
+// appendSandboxed appends the tags with their keys prefixed with the namespace's tag prefix to dst.
+func (n *Namespace) appendSandboxed(dst, tags []Tag) []Tag {
+	for tag := range allTags(tags) {
+		dst = append(dst, Tag{Key: n.sandboxedKey(tag.Key), Value: tag.Value})
+	}
+
+	return dst
+}
+
+// sandboxedKey returns the key prefixed with the namespace's tag prefix, cached so repeated
+// keys don't allocate.
+func (n *Namespace) sandboxedKey(key string) string {
+	if prefixed, ok := n.sandboxed.keys.Load(key); ok {
+		return prefixed.(string) //nolint:forcetypeassert
+	}
+
+	prefixed := n.tagPrefix + key
+
+	if n.sandboxed.size.Load() < maxCachedTagKeys {
+		if _, loaded := n.sandboxed.keys.LoadOrStore(key, prefixed); !loaded {
+			n.sandboxed.size.Add(1)
+		}
+	}
+
+	return prefixed
+}
+
+// sendSandboxed queues the metric of a delegated namespace with its tags sandboxed.
+func (c *Client) sendSandboxed(ns *Namespace, key, mt string, v value, tags []Tag) {
+	sandboxed := getTags()
+	defer putTags(sandboxed)
+
+	*sandboxed = ns.appendSandboxed(*sandboxed, tags)
+
+	c.sendValues(ns, key, mt, *sandboxed, v)
+}
@@ -0,0 +1,18 @@
+//go:build !js && !wasip1
+
+package statsd
+
+import (
+	"fmt"
+	"net"
+)
+
+// dialNetwork connects to the address on the network.
+func dialNetwork(network, address string) (net.Conn, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+
+	return conn, nil
+}
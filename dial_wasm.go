@@ -0,0 +1,16 @@
+//go:build js || wasip1
+
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// dialNetwork fails, as WASM runtimes have no sockets to dial; clients there send through a Sink,
+// e.g. an HTTPSink, or a Writer.
+func dialNetwork(network, address string) (net.Conn, error) {
+	return nil, fmt.Errorf("statsd: dial %s %s: %w, send through the Output or Writer option",
+		network, address, errors.ErrUnsupported)
+}
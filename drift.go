@@ -0,0 +1,50 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ConfigTags returns tags identifying the effective configuration of the client: its hash (TagConfigHash)
+// and the target metrics are sent to (TagTarget), i.e. the server address, or the transport if the client
+// doesn't dial a server itself, e.g. "writer".
+func (c *Client) ConfigTags() []Tag {
+	cfg := c.Config()
+
+	target := cfg.Address
+
+	switch {
+	case c.autoDetect:
+		target = c.addr
+	case target == "":
+		target = cfg.Transport
+	}
+
+	return []Tag{
+		{Key: TagConfigHash, Value: cfg.Hash()},
+		{Key: TagTarget, Value: target},
+	}
+}
+
+// ReportConfig sends a gauge with the key and value 1, tagged with ConfigTags, every interval until ctx
+// is done, so infrastructure teams can find services with outdated configurations, e.g. pointing at
+// deprecated aggregators. Run it in its own goroutine:
+//
+//	go client.ReportConfig(ctx, time.Minute, "statsd.config")
+//
+// It returns the context's error.
+func (c *Client) ReportConfig(ctx context.Context, interval time.Duration, key string) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.Gauge(key, 1, c.ConfigTags()...)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("statsd: %w", ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package statsd
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// TagErrorClass is the tag key of the error counter set by the ErrorCounter option.
+const TagErrorClass = "class"
+
+// errorClasses are the values of the error class tag, in the order of the checks in classifyError.
+var errorClasses = [...]string{ //nolint:gochecknoglobals
+	"connection_refused",
+	"line_too_long",
+	"invalid_name",
+	"unsupported_type",
+	"undefined_metric",
+	"type_mismatch",
+	"tag_not_allowed",
+	"flush_stalled",
+	"write",
+	"other",
+}
+
+// classifiedErrors are the errors classified by their own class, in the order of errorClasses.
+var classifiedErrors = [...]error{ //nolint:gochecknoglobals
+	ErrConnectionRefused,
+	ErrLineTooLong,
+	ErrInvalidName,
+	ErrUnsupportedType,
+	ErrUndefinedMetric,
+	ErrMetricType,
+	ErrTagNotAllowed,
+	ErrFlushStalled,
+}
+
+// classifyError returns the index of the class of the error in errorClasses.
+func classifyError(err error) int {
+	for i, target := range classifiedErrors {
+		if errors.Is(err, target) {
+			return i
+		}
+	}
+
+	var werr *WriteError
+	if errors.As(err, &werr) {
+		return len(classifiedErrors)
+	}
+
+	return len(errorClasses) - 1
+}
+
+// errorCounter counts the errors reported by a client per class and sends the counts as a counter.
+// The counts are sent in their own small payload after every flush, bypassing the queue, so they
+// are never dropped with the metrics they describe, and failures to send them are not reported
+// as errors, so they can't feed themselves.
+type errorCounter struct {
+	key    string
+	counts [len(errorClasses)]atomic.Uint64
+	sent   [len(errorClasses)]uint64 // Counts already sent, only accessed by the flusher
+	buf    []byte
+}
+
+// newErrorCounter returns an error counter sending the counts under the key, or nil if the key is empty.
+func newErrorCounter(key string) *errorCounter {
+	if key == "" {
+		return nil
+	}
+
+	return &errorCounter{key: key, counts: [len(errorClasses)]atomic.Uint64{}, sent: [len(errorClasses)]uint64{}, buf: nil}
+}
+
+// add counts the error.
+func (e *errorCounter) add(err error) {
+	e.counts[classifyError(err)].Add(1)
+}
+
+// flush sends the errors counted since the last successful flush. Must be called with the flush lock held.
+func (e *errorCounter) flush(c *Client) {
+	e.buf = e.buf[:0]
+
+	var counts [len(errorClasses)]uint64
+
+	for i := range e.counts {
+		counts[i] = e.counts[i].Load()
+
+		if delta := counts[i] - e.sent[i]; delta > 0 {
+			tags := [1]Tag{{Key: TagErrorClass, Value: errorClasses[i]}}
+
+			e.buf, _ = c.serializer.appendLine(e.buf, "", e.key, intValue(int64(delta)), "c", tags[:])
+		}
+	}
+
+	if len(e.buf) == 0 {
+		return
+	}
+
+	for rest := e.buf[:len(e.buf)-1]; len(rest) > 0; {
+		var datagram []byte
+
+		datagram, rest = splitDatagram(rest, c.maxBufferSize)
+
+		if c.serializer.framed() {
+			c.frame = c.serializer.frame(c.frame[:0], datagram)
+			datagram = c.frame
+		}
+
+		if _, err := c.getConn().Write(datagram); err != nil {
+			// Keep the counts to send them with the next flush
+			return
+		}
+
+		c.stats.bytes.Add(uint64(len(datagram)))
+		c.stats.datagrams.Add(1)
+		c.sinkStats.written(datagram)
+	}
+
+	e.sent = counts
+}
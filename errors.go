@@ -0,0 +1,191 @@
+package statsd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// ErrConnectionRefused is reported when the StatsD server refused a payload, which on
+// connected UDP sockets means that an earlier datagram was answered with ICMP "port
+// unreachable", usually because the agent is gone. Check for it with errors.Is.
+var ErrConnectionRefused = errors.New("statsd: connection refused")
+
+// ErrLineTooLong is reported when a metric line exceeds the maximum line length and is dropped.
+var ErrLineTooLong = errors.New("statsd: line too long")
+
+// ErrInvalidName is reported when a metric is dropped because its name contains unsafe bytes.
+var ErrInvalidName = errors.New("statsd: invalid metric name")
+
+// ErrUnsupportedType is reported when a metric of an extended type is dropped because the
+// ExtendedTypes option isn't set.
+var ErrUnsupportedType = errors.New("statsd: unsupported metric type")
+
+// ErrUndefinedMetric is reported when a metric is dropped in strict mode because it isn't defined.
+var ErrUndefinedMetric = errors.New("statsd: undefined metric")
+
+// ErrMetricType is reported when a metric is dropped in strict mode because it was sent with
+// another type than defined, e.g. as a gauge instead of a counter.
+var ErrMetricType = errors.New("statsd: metric type mismatch")
+
+// ErrTagNotAllowed is reported when a metric is dropped in strict mode because it has a tag
+// its definition doesn't allow.
+var ErrTagNotAllowed = errors.New("statsd: tag not allowed")
+
+// ErrReservedTag is reported when a per-call or namespace tag with a reserved key is removed from
+// a metric. See the ReservedTags option.
+var ErrReservedTag = errors.New("statsd: reserved tag key")
+
+// ErrMalformedLine is returned by ParseLine for lines that aren't valid metric lines.
+var ErrMalformedLine = errors.New("statsd: malformed line")
+
+// ErrClosed is returned by Registry.Client once the registry is closed.
+var ErrClosed = errors.New("statsd: registry closed")
+
+// ErrFlushStalled is reported when the background flusher didn't complete a flush within the stall timeout.
+var ErrFlushStalled = errors.New("statsd: flusher stalled")
+
+// PartialWriteError is returned by sinks that write a payload in parts, e.g. DataDogSink posting to two APIs,
+// when some of the parts failed. The client retries and retains only the unwritten part, so the metrics
+// written aren't sent twice.
+type PartialWriteError struct {
+	Err error
+	// Unwritten holds the whole lines of the payload that weren't written.
+	Unwritten []byte
+}
+
+// Error returns the message of the underlying error.
+func (e *PartialWriteError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *PartialWriteError) Unwrap() error {
+	return e.Err
+}
+
+// unwritten returns the lines of the payload that weren't written because of the error: the unwritten
+// part of a *PartialWriteError, or else the whole payload.
+func unwritten(payload []byte, err error) []byte {
+	var partial *PartialWriteError
+	if errors.As(err, &partial) {
+		return partial.Unwritten
+	}
+
+	return payload
+}
+
+// WriteError is reported when a datagram of a flush couldn't be written. When a flush is split
+// into several datagrams, a WriteError is reported per failed datagram, telling which one failed
+// and how many metrics it held, and optionally the names of some of them, so it is clear whose
+// metrics were lost. See the ErrorMetricNames and RetainFailedChunks options.
+type WriteError struct {
+	Err error
+	// Metrics are the distinct names of the first metrics of the datagram, prefix included.
+	Metrics []string
+	// Lines is the number of metric lines of the datagram.
+	Lines int
+	// Chunk is the index of the datagram among the datagrams of the flush.
+	Chunk int
+	// Chunks is the number of datagrams of the flush.
+	Chunks int
+	// Retained reports whether the lines were kept to be written again with the next flush
+	// instead of being lost.
+	Retained bool
+}
+
+// newWriteError returns the error of writing the lines, naming at most n of their metrics.
+func newWriteError(err error, lines []byte, n int) *WriteError {
+	werr := &WriteError{Err: err, Metrics: nil, Lines: 0, Chunk: 0, Chunks: 1, Retained: false}
+
+	for _, line := range bytes.Split(lines, []byte{'\n'}) {
+		werr.Lines++
+
+		name := line
+		if i := bytes.IndexAny(line, ":;,"); i >= 0 {
+			name = line[:i]
+		}
+
+		if len(werr.Metrics) < n && !slices.Contains(werr.Metrics, string(name)) {
+			werr.Metrics = append(werr.Metrics, string(name))
+		}
+	}
+
+	return werr
+}
+
+// Error returns the error message followed by the number of metrics and their names, if any.
+func (e *WriteError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s (", e.Err)
+
+	if e.Chunks > 1 {
+		fmt.Fprintf(&b, "datagram %d of %d, ", e.Chunk+1, e.Chunks)
+	}
+
+	fmt.Fprintf(&b, "%d metrics", e.Lines)
+
+	if len(e.Metrics) > 0 {
+		b.WriteString(": " + strings.Join(e.Metrics, ", "))
+	}
+
+	if e.Retained {
+		b.WriteString(", retained")
+	}
+
+	b.WriteByte(')')
+
+	return b.String()
+}
+
+// Unwrap returns the write error.
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// UnsentError is returned by Shutdown if the client couldn't send all its metrics before the deadline.
+// The counts are a snapshot at the deadline; the client keeps closing in the background.
+type UnsentError struct {
+	Err error
+	// Metrics is the number of metrics queued or being written.
+	Metrics int
+	// Bytes is the size of these metrics, estimated for the metrics not serialized yet.
+	Bytes int
+}
+
+// Error returns the error message followed by the unsent metrics and bytes.
+func (e *UnsentError) Error() string {
+	return fmt.Sprintf("statsd: %s (%d metrics, %d bytes unsent)", e.Err, e.Metrics, e.Bytes)
+}
+
+// Unwrap returns the error of the context.
+func (e *UnsentError) Unwrap() error {
+	return e.Err
+}
+
+// connError wraps an error returned by the connection, singling out refused connections.
+func connError(err error) error {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("%w: %w", ErrConnectionRefused, err)
+	}
+
+	return fmt.Errorf("statsd: %w", err)
+}
+
+// retryable reports whether a failed write may succeed when repeated, i.e. the error is transient.
+func retryable(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.EAGAIN) ||
+		errors.Is(err, syscall.EINTR) ||
+		errors.Is(err, syscall.ENOBUFS) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
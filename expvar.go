@@ -0,0 +1,70 @@
+package statsd
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ReportExpvar emits the numeric variables published with the expvar package as gauges every
+// interval, until ctx is done. Maps and JSON objects, e.g. "memstats", are walked and their fields
+// named "var.field". The mapping returns the metric name of a variable, or an empty string to skip
+// it; a nil mapping keeps the names. Run it in its own goroutine:
+//
+//	go client.ReportExpvar(ctx, 10*time.Second, nil)
+//
+// It returns the context's error.
+func (c *Client) ReportExpvar(ctx context.Context, interval time.Duration, mapping func(name string) string) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.reportExpvar(mapping)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("statsd: %w", ctx.Err())
+		}
+	}
+}
+
+// reportExpvar emits the current values of the expvar variables.
+func (c *Client) reportExpvar(mapping func(name string) string) {
+	expvar.Do(func(kv expvar.KeyValue) {
+		var v any
+		if err := json.Unmarshal([]byte(kv.Value.String()), &v); err != nil {
+			return
+		}
+
+		c.reportExpvarValue(mapping, kv.Key, v)
+	})
+}
+
+// reportExpvarValue emits the value as a gauge if it is a number, or walks it if it is an object.
+func (c *Client) reportExpvarValue(mapping func(name string) string, name string, v any) {
+	switch v := v.(type) {
+	case float64:
+		if mapping != nil {
+			name = mapping(name)
+		}
+
+		if name != "" {
+			c.Gauge(name, v)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			c.reportExpvarValue(mapping, name+"."+sanitizeSegment(key), v[key])
+		}
+	}
+}
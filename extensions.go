@@ -0,0 +1,26 @@
+package statsd
+
+import "fmt"
+
+// KeyValue sends a key/value pair ("key:value|kv"), which statsite records as is.
+// It requires the ExtendedTypes option.
+func (c *Client) KeyValue(key, value string, tags ...Tag) {
+	c.sendExtended(key, "kv", stringValue(value), tags)
+}
+
+// Meter sends a meter ("key:value|m"), a counter whose server computes its rates.
+// It requires the ExtendedTypes option.
+func (c *Client) Meter(key string, value int64, tags ...Tag) {
+	c.sendExtended(key, "m", intValue(value), tags)
+}
+
+// sendExtended queues a metric of an extended type if the client supports it.
+func (c *Client) sendExtended(key, mt string, v value, tags []Tag) {
+	if !c.extendedTypes {
+		c.reportError(fmt.Errorf("%w: %q", ErrUnsupportedType, mt))
+
+		return
+	}
+
+	c.send(key, mt, v, tags)
+}
@@ -0,0 +1,76 @@
+package statsd
+
+import (
+	"path"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCachedFilters bounds the number of metric names whose filter decision is cached.
+const maxCachedFilters = 4096
+
+// metricFilter holds the glob patterns of the names of metrics dropped at runtime.
+type metricFilter struct {
+	patterns []string
+	cache    sync.Map // Metric name to bool
+	size     atomic.Int64
+}
+
+// DropMetrics drops the metrics whose names, including the namespace, match glob patterns,
+// e.g. "debug.*", until it is called again, e.g. to stop a metric storm without a deploy.
+// Calling it without patterns sends all metrics again. Registered instruments are dropped alike when
+// they are emitted, so the counts of registered counters dropped meanwhile are lost.
+func (c *Client) DropMetrics(patterns ...string) {
+	if len(patterns) == 0 {
+		c.filter.Store(nil)
+
+		return
+	}
+
+	c.filter.Store(&metricFilter{patterns: slices.Clone(patterns), cache: sync.Map{}, size: atomic.Int64{}})
+}
+
+// DroppedMetrics returns the patterns set with DropMetrics.
+func (c *Client) DroppedMetrics() []string {
+	if f := c.filter.Load(); f != nil {
+		return slices.Clone(f.patterns)
+	}
+
+	return nil
+}
+
+// drops reports whether the metric name, including the namespace, matches a pattern.
+// A nil filter drops nothing.
+func (f *metricFilter) drops(ns *Namespace, key string) bool {
+	if f == nil {
+		return false
+	}
+
+	name := key
+	if ns != nil {
+		name = ns.prefix + key
+	}
+
+	if cached, ok := f.cache.Load(name); ok {
+		return cached.(bool) //nolint:forcetypeassert
+	}
+
+	drop := false
+
+	for _, pattern := range f.patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			drop = true
+
+			break
+		}
+	}
+
+	if f.size.Load() < maxCachedFilters {
+		if _, loaded := f.cache.LoadOrStore(name, drop); !loaded {
+			f.size.Add(1)
+		}
+	}
+
+	return drop
+}
@@ -0,0 +1,27 @@
+package statsd_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/devem-tech/statsd"
+)
+
+func TestDropMetricsDropsRegisteredInstruments(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := statsd.New(statsd.Writer(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.RegisterCounter("debug.cache.hits").Add(3)
+	client.RegisterGauge("connections").Set(2)
+	client.DropMetrics("debug.*")
+	client.Increment("debug.requests")
+	client.Close()
+
+	if got, want := buf.String(), "connections:2|g"; got != want {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,27 @@
+package statsd
+
+import "time"
+
+// FlushScheduler is a background flusher shared by several clients, e.g. one per plugin or tenant,
+// so a process runs a single flushing goroutine however many clients it creates.
+type FlushScheduler struct {
+	scheduler *scheduler
+}
+
+// NewFlushScheduler starts a flusher that flushes its clients every interval.
+// The FlushInterval option has no effect on clients using it.
+func NewFlushScheduler(interval time.Duration) *FlushScheduler {
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+
+	return &FlushScheduler{
+		scheduler: newScheduler(interval),
+	}
+}
+
+// Stop stops the flusher. Close its clients first: metrics of the remaining clients are only
+// flushed when they are closed.
+func (s *FlushScheduler) Stop() {
+	s.scheduler.stop()
+}
@@ -0,0 +1,219 @@
+package statsd
+
+import (
+	"hash/maphash"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// gaugeShards is the number of shards of a gauge cache, a power of two.
+const gaugeShards = 64
+
+// gaugeCache keeps the last value of every gauge sent since the last flush, so a gauge updated many
+// times per flush interval is sent once. The first update of a gauge per flush queues it like any metric,
+// holding a slot instead of its value; later updates only store the value in the slot, which is read when
+// the gauge is flushed. Slots are kept in shards of lock-free maps and updated atomically, so distinct
+// gauges are updated concurrently without locks.
+type gaugeCache struct {
+	seed     maphash.Seed
+	shards   [gaugeShards]sync.Map // Hashes of the gauges to their *gaugeSlot
+	interval time.Duration         // Time between sweeps
+	swept    time.Time             // Time of the last sweep, guarded by the flush lock
+}
+
+// gaugeSlot holds the last value of a gauge.
+type gaugeSlot struct {
+	ns       *Namespace
+	key      string
+	tags     []Tag
+	priority bool
+	hash     uint64
+	shard    *sync.Map
+	bits     atomic.Uint64 // math.Float64bits of the value
+	state    atomic.Int32  // One of the gauge states
+}
+
+// States of a gauge slot. A queued slot is updated in place. A flushed slot is idle until it is
+// updated, which queues it again, and freed by the second sweep that finds it idle, see sweep. A slot
+// evicted or dropped from the queue is sealed: its queued value must not change anymore,
+// so the next update replaces it.
+const (
+	gaugeQueued int32 = iota
+	gaugeIdle
+	gaugeStale
+	gaugeSealed
+)
+
+// aggregate keeps an unsampled gauge with a single value in the gauge cache, if enabled.
+// It reports whether the gauge was kept; other gauges are queued after the cached value
+// of the same gauge, which is sent as it is.
+func (c *Client) aggregate(ns *Namespace, key, mt string, tags []Tag, priority bool, values []value) bool {
+	if c.gauges == nil || mt != "g" {
+		return false
+	}
+
+	if len(values) != 1 || values[0].kind != floatKind || values[0].rate < 1 || values[0].timestamp != 0 {
+		c.gauges.evict(ns, key, tags)
+
+		return false
+	}
+
+	return c.gauges.store(c, ns, key, tags, priority, values[0].f)
+}
+
+// newGaugeCache returns an empty gauge cache for a client flushed every interval.
+func newGaugeCache(interval time.Duration) *gaugeCache {
+	return &gaugeCache{seed: maphash.MakeSeed(), shards: [gaugeShards]sync.Map{}, interval: interval, swept: time.Now()}
+}
+
+// store sets the value of the gauge, queueing it if it isn't queued yet. It reports false
+// if the gauge can't be kept, i.e. its hash collides with another gauge, which is then sent as usual.
+func (g *gaugeCache) store(c *Client, ns *Namespace, key string, tags []Tag, priority bool, value float64) bool {
+	h := g.hash(ns, key, tags)
+	shard := &g.shards[h&(gaugeShards-1)]
+	bits := math.Float64bits(value)
+
+	for {
+		v, ok := shard.Load(h)
+		if !ok {
+			slot := &gaugeSlot{
+				ns:       ns,
+				key:      key,
+				tags:     slices.Clone(tags),
+				priority: priority,
+				hash:     h,
+				shard:    shard,
+				bits:     atomic.Uint64{},
+				state:    atomic.Int32{},
+			}
+			slot.bits.Store(bits)
+
+			if _, loaded := shard.LoadOrStore(h, slot); loaded {
+				continue
+			}
+
+			c.enqueue(1, slot.push)
+
+			return true
+		}
+
+		slot := v.(*gaugeSlot) //nolint:forcetypeassert
+		if !slot.matches(ns, key, tags) {
+			return false
+		}
+
+		// The value is stored before the state is checked, and the flusher reads it after changing
+		// the state, so a queued slot is flushed with the value
+		slot.bits.Store(bits)
+
+		for {
+			switch state := slot.state.Load(); state {
+			case gaugeQueued:
+				c.stats.metrics.Add(1)
+
+				return true
+			case gaugeIdle, gaugeStale:
+				if slot.state.CompareAndSwap(state, gaugeQueued) {
+					c.enqueue(1, slot.push)
+
+					return true
+				}
+
+				continue
+			}
+
+			break
+		}
+
+		shard.CompareAndDelete(h, slot)
+	}
+}
+
+// evict seals the slot of the gauge, so the gauge is queued again on its next update, after the metrics
+// queued meanwhile.
+func (g *gaugeCache) evict(ns *Namespace, key string, tags []Tag) {
+	h := g.hash(ns, key, tags)
+
+	v, ok := g.shards[h&(gaugeShards-1)].Load(h)
+	if !ok {
+		return
+	}
+
+	if slot := v.(*gaugeSlot); slot.matches(ns, key, tags) { //nolint:forcetypeassert
+		slot.seal()
+	}
+}
+
+// sweep frees the slots found idle by the previous sweep, i.e. gauges not updated for a flush interval.
+// Flushes of full buffers in between don't sweep.
+func (g *gaugeCache) sweep() {
+	now := time.Now()
+	if now.Sub(g.swept) < g.interval {
+		return
+	}
+
+	g.swept = now
+
+	for i := range g.shards {
+		g.shards[i].Range(func(_, v any) bool {
+			slot := v.(*gaugeSlot) //nolint:forcetypeassert
+
+			if slot.state.CompareAndSwap(gaugeStale, gaugeSealed) {
+				slot.shard.CompareAndDelete(slot.hash, slot)
+			} else {
+				slot.state.CompareAndSwap(gaugeIdle, gaugeStale)
+			}
+
+			return true
+		})
+	}
+}
+
+// hash returns the hash of the gauge's namespace prefix, key and tags.
+func (g *gaugeCache) hash(ns *Namespace, key string, tags []Tag) uint64 {
+	var h maphash.Hash
+
+	h.SetSeed(g.seed)
+
+	if ns != nil {
+		h.WriteString(ns.prefix)
+	}
+
+	h.WriteByte(0)
+	h.WriteString(key)
+
+	for _, tag := range tags {
+		h.WriteByte(0)
+		h.WriteString(tag.Key)
+		h.WriteByte(0)
+		h.WriteString(tag.Value)
+	}
+
+	return h.Sum64()
+}
+
+// matches reports whether the slot holds the gauge.
+func (s *gaugeSlot) matches(ns *Namespace, key string, tags []Tag) bool {
+	return s.ns == ns && s.key == key && slices.Equal(s.tags, tags)
+}
+
+// push appends the slot to the queue and returns the new estimated size.
+func (s *gaugeSlot) push(q *queue) int {
+	return q.pushGauge(s)
+}
+
+// flush returns the value of the slot being flushed, leaving it idle unless it is sealed.
+func (s *gaugeSlot) flush() value {
+	s.state.CompareAndSwap(gaugeQueued, gaugeIdle)
+
+	return floatValue(math.Float64frombits(s.bits.Load()))
+}
+
+// seal removes the slot from the cache, so its queued value doesn't change anymore.
+func (s *gaugeSlot) seal() {
+	s.state.Store(gaugeSealed)
+	s.shard.CompareAndDelete(s.hash, s)
+}
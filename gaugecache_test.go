@@ -0,0 +1,112 @@
+package statsd_test
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/devem-tech/statsd"
+)
+
+// gaugeKeys is the number of distinct gauges of the gauge cache benchmarks, and hotGaugeKeys the number
+// of gauges updated many times per flush.
+const (
+	gaugeKeys    = 100_000
+	hotGaugeKeys = 1_000
+)
+
+func BenchmarkGaugeCache(b *testing.B) {
+	keys := make([]string, gaugeKeys)
+	for i := range keys {
+		keys[i] = "gauge." + strconv.Itoa(i)
+	}
+
+	for _, n := range []int{gaugeKeys, hotGaugeKeys} {
+		for _, aggregate := range []bool{false, true} {
+			b.Run("keys="+strconv.Itoa(n)+"/aggregate="+strconv.FormatBool(aggregate), func(b *testing.B) {
+				client, err := statsd.New(statsd.Writer(io.Discard), statsd.AggregateGauges(aggregate))
+				if err != nil {
+					b.Fatal(err)
+				}
+				defer client.Close()
+
+				b.ReportAllocs()
+				b.RunParallel(func(pb *testing.PB) {
+					i := 0
+					for pb.Next() {
+						client.Gauge(keys[i%n], float64(i))
+						i += 7
+					}
+				})
+			})
+		}
+	}
+}
+
+func TestAggregateGaugesKeepsOrder(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := statsd.New(statsd.Writer(&buf), statsd.AggregateGauges(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.Gauge("pool", 5)
+	client.Gauge("pool", 6)
+	client.Gauge("other", 1)
+	client.GaugeAbsolute("pool", -3)
+	client.Gauge("other", 2)
+	client.Close()
+
+	if got, want := buf.String(), "pool:6|g\nother:2|g\npool:0|g\npool:-3|g"; got != want {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestAggregateGaugesAfterQueuedGauge(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := statsd.New(statsd.Writer(&buf), statsd.AggregateGauges(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.GaugeAbsolute("pool", -3)
+	client.Gauge("pool", 5)
+	client.Gauge("pool", 6)
+	client.Close()
+
+	if got, want := buf.String(), "pool:0|g\npool:-3|g\npool:6|g"; got != want {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestAggregateGaugesFlushWhenFull(t *testing.T) {
+	var buf syncBuffer
+
+	client, err := statsd.New(
+		statsd.Writer(&buf),
+		statsd.AggregateGauges(true),
+		statsd.FlushInterval(time.Hour),
+		statsd.MaxBufferSize(256),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	for i := range 100 {
+		client.Gauge("gauge."+strconv.Itoa(i), float64(i))
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for buf.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if buf.String() == "" {
+		t.Error("full buffer of aggregated gauges wasn't flushed")
+	}
+}
@@ -0,0 +1,114 @@
+package statsd
+
+import (
+	"sync"
+	"time"
+)
+
+// SinkState is the state of a destination of the metrics.
+type SinkState int
+
+const (
+	// SinkUnknown means nothing was written to the destination yet.
+	SinkUnknown SinkState = iota
+	// SinkHealthy means the last write to the destination succeeded.
+	SinkHealthy
+	// SinkFailing means the last write to the destination failed.
+	SinkFailing
+)
+
+// String returns the name of the state.
+func (s SinkState) String() string {
+	switch s {
+	case SinkHealthy:
+		return "healthy"
+	case SinkFailing:
+		return "failing"
+	case SinkUnknown:
+	}
+
+	return "unknown"
+}
+
+// SinkHealth is the status of writes to a destination of the metrics.
+type SinkHealth struct {
+	State               SinkState
+	LastError           error
+	LastErrorTime       time.Time
+	LastSuccessTime     time.Time
+	ConsecutiveFailures int
+}
+
+// health tracks the status of writes to a destination.
+type health struct {
+	lock   sync.Mutex
+	status SinkHealth
+}
+
+// newHealth returns the status of a destination nothing was written to yet.
+func newHealth() health {
+	return health{
+		lock: sync.Mutex{},
+		status: SinkHealth{
+			State:               SinkUnknown,
+			LastError:           nil,
+			LastErrorTime:       time.Time{},
+			LastSuccessTime:     time.Time{},
+			ConsecutiveFailures: 0,
+		},
+	}
+}
+
+// success records a successful write.
+func (h *health) success() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.status.State = SinkHealthy
+	h.status.LastSuccessTime = time.Now()
+	h.status.ConsecutiveFailures = 0
+}
+
+// get returns the status.
+func (h *health) get() SinkHealth {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.status
+}
+
+// failure records a failed write.
+func (h *health) failure(err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.status.State = SinkFailing
+	h.status.LastError = err
+	h.status.LastErrorTime = time.Now()
+	h.status.ConsecutiveFailures++
+}
+
+// Health returns the status of writes per destination, keyed by the address of the StatsD server,
+// or by the transport if the client doesn't dial it (see Config), and by the name of every mirror
+// (see MirrorTo), e.g. for readiness endpoints.
+func (c *Client) Health() map[string]SinkHealth {
+	health := map[string]SinkHealth{c.destination(): c.health.get()}
+
+	for _, e := range c.encodings {
+		for _, m := range e.mirrors {
+			health[m.name] = m.health.get()
+		}
+	}
+
+	return health
+}
+
+// destination returns the name of the destination of the client: the address of the StatsD server,
+// or the transport if the client doesn't dial it.
+func (c *Client) destination() string {
+	if c.config.Address != "" {
+		return c.config.Address
+	}
+
+	return c.config.Transport
+}
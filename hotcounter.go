@@ -0,0 +1,56 @@
+package statsd
+
+import "sync/atomic"
+
+// HotCounter is a counter for the hottest code paths: Inc is a single atomic add, and the sum
+// is drained into the client once per flush interval. Unlike registered counters, hot counters
+// never expire, so updates don't have to mark them as used.
+type HotCounter struct {
+	instrument instrument // Not embedded, as hot counters don't expire
+
+	_     [64]byte // Keep the count on its own cache line
+	count atomic.Int64
+	_     [56]byte
+}
+
+// HotCounter returns the hot counter registered under the key and tags, registering it if needed.
+// Hot counters are registered apart from registered counters with the same key and tags.
+func (c *Client) HotCounter(key string, tags ...Tag) *HotCounter {
+	inst := c.instruments.register(c, "hot", "c", key, tags, func() registered {
+		return new(HotCounter)
+	})
+
+	h := inst.(*HotCounter)
+	h.instrument.SetTTL(0)
+
+	return h
+}
+
+// Inc increases the counter by 1.
+func (h *HotCounter) Inc() {
+	h.count.Add(1)
+}
+
+// Add increases the counter by value.
+func (h *HotCounter) Add(value int64) {
+	h.count.Add(value)
+}
+
+// Describe documents the counter with a description and a unit, see RegisteredCounter.Describe.
+func (h *HotCounter) Describe(description, unit string) {
+	h.instrument.Describe(description, unit)
+}
+
+func (h *HotCounter) base() *instrument {
+	return &h.instrument
+}
+
+func (h *HotCounter) collect(c *Client) {
+	if count := h.count.Swap(0); count != 0 {
+		c.send(h.instrument.key, "c", intValue(count), h.instrument.tags)
+	}
+}
+
+func (h *HotCounter) value() float64 {
+	return float64(h.count.Load())
+}
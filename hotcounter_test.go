@@ -0,0 +1,45 @@
+package statsd_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/devem-tech/statsd"
+)
+
+func TestHotCounterSentAsCounter(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := statsd.New(statsd.Writer(&buf), statsd.CallerDriven(), statsd.InstrumentTTL(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.HotCounter("requests").Add(3)
+	client.RegisterCounter("requests").Add(2)
+
+	// A registered counter of the same name is registered apart
+	if snapshot := client.Snapshot(); len(snapshot) != 2 || snapshot[0].Type != "c" || snapshot[1].Type != "c" {
+		t.Errorf("snapshot %+v, want two counters", snapshot)
+	}
+
+	now := time.Now()
+	client.Tick(now)
+
+	// Idle intervals expire the registered counter only
+	for range 3 {
+		now = now.Add(time.Hour)
+		client.Tick(now)
+	}
+
+	if snapshot := client.Snapshot(); len(snapshot) != 1 || snapshot[0].Type != "c" {
+		t.Errorf("snapshot %+v, want the hot counter", snapshot)
+	}
+
+	client.Close()
+
+	if got := buf.String(); got != "requests:3|c\nrequests:2|c" && got != "requests:2|c\nrequests:3|c" {
+		t.Errorf("sent %q", got)
+	}
+}
@@ -0,0 +1,279 @@
+package statsd
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// instruments holds the instruments registered with a client. Registered instruments
+// keep their value locally and are emitted by the background flusher once per flush interval.
+type instruments struct {
+	lock          sync.Mutex
+	instruments   map[string]registered
+	ttl           int
+	reservoirSize int
+}
+
+// registered is an instrument held by the registry.
+type registered interface {
+	base() *instrument
+	collect(c *Client)
+	value() float64
+}
+
+// MetricSnapshot is the current state of a registered instrument.
+type MetricSnapshot struct {
+	Key  string
+	Type string
+	Tags []Tag
+	// Value is the last value of a gauge, the sum a counter accumulated since the last flush,
+	// the number of timings a timer recorded since the last flush, the largest value a top-K
+	// instrument observed since the last flush, the events per second of a rate, the current
+	// size of a size gauge, or the current value of a stateful gauge.
+	Value float64
+}
+
+// instrument holds the state shared by all registered instruments.
+type instrument struct {
+	client  *Client
+	id      string
+	mt      string
+	key     string
+	tags    []Tag
+	ttl     atomic.Int64
+	touched atomic.Bool
+	expired atomic.Bool
+	idle    int // Only accessed under the registry lock
+
+	description string // Only accessed under the registry lock
+	unit        string // Only accessed under the registry lock
+}
+
+// newInstruments returns an empty registry whose instruments expire after ttl idle intervals
+// and whose timers keep reservoirSize timings per interval.
+func newInstruments(ttl, reservoirSize int) *instruments {
+	return &instruments{
+		lock:          sync.Mutex{},
+		instruments:   make(map[string]registered),
+		ttl:           ttl,
+		reservoirSize: reservoirSize,
+	}
+}
+
+// instrumentID returns the identity of an instrument within the registry.
+func instrumentID(kind, key string, tags []Tag) string {
+	var b strings.Builder
+
+	b.WriteString(kind)
+	b.WriteByte('|')
+	b.WriteString(key)
+
+	for _, tag := range tags {
+		b.WriteByte(0)
+		b.WriteString(tag.Key)
+		b.WriteByte('=')
+		b.WriteString(tag.Value)
+	}
+
+	return b.String()
+}
+
+// register returns the instrument of the kind registered under the key and tags, registering the one
+// returned by create, emitted with the metric type, if it doesn't exist. The kind tells instruments
+// emitted with the same metric type apart, e.g. hot counters from registered counters.
+func (r *instruments) register(c *Client, kind, mt, key string, tags []Tag, create func() registered) registered {
+	id := instrumentID(kind, key, tags)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if inst, ok := r.instruments[id]; ok {
+		return inst
+	}
+
+	inst := create()
+
+	b := inst.base()
+	b.client = c
+	b.id = id
+	b.mt = mt
+	b.key = key
+	b.tags = tags
+	b.ttl.Store(int64(r.ttl))
+
+	r.instruments[id] = inst
+
+	return inst
+}
+
+// touch marks the instrument as updated, registering it again if it has expired.
+func (r *instruments) touch(inst registered) {
+	b := inst.base()
+	b.touched.Store(true)
+
+	if !b.expired.Load() {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if b.expired.CompareAndSwap(true, false) {
+		b.idle = 0
+		r.instruments[b.id] = inst
+	}
+}
+
+// collect emits all instruments and frees the ones that stayed idle for longer than their TTL.
+func (r *instruments) collect(c *Client) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for id, inst := range r.instruments {
+		b := inst.base()
+
+		if b.touched.Swap(false) {
+			b.idle = 0
+		} else {
+			b.idle++
+		}
+
+		if ttl := int(b.ttl.Load()); ttl > 0 && b.idle >= ttl {
+			b.expired.Store(true)
+			delete(r.instruments, id)
+
+			continue
+		}
+
+		inst.collect(c)
+	}
+}
+
+// Snapshot returns the current values of the registered instruments, ordered by key and type.
+// It is meant for debugging endpoints and assertions in tests.
+func (c *Client) Snapshot() []MetricSnapshot {
+	c.instruments.lock.Lock()
+	defer c.instruments.lock.Unlock()
+
+	snapshots := make([]MetricSnapshot, 0, len(c.instruments.instruments))
+
+	for _, inst := range c.instruments.instruments {
+		b := inst.base()
+
+		snapshots = append(snapshots, MetricSnapshot{
+			Key:   b.key,
+			Type:  b.mt,
+			Tags:  expandTags(b.tags),
+			Value: inst.value(),
+		})
+	}
+
+	slices.SortFunc(snapshots, func(a, b MetricSnapshot) int {
+		return cmp.Or(cmp.Compare(a.Key, b.Key), cmp.Compare(a.Type, b.Type))
+	})
+
+	return snapshots
+}
+
+// SetTTL sets the number of flush intervals the instrument may stay untouched before it stops
+// being emitted and is freed. Zero means the instrument never expires. Updating an expired
+// instrument registers it again.
+func (i *instrument) SetTTL(intervals int) {
+	i.ttl.Store(int64(intervals))
+}
+
+// Describe documents the instrument with a description and a unit, e.g. "bytes" or "requests".
+// The StatsD protocol has no metadata, so they are only published by ExportSchema.
+func (i *instrument) Describe(description, unit string) {
+	i.client.instruments.lock.Lock()
+	defer i.client.instruments.lock.Unlock()
+
+	i.description = description
+	i.unit = unit
+}
+
+// RegisteredCounter is a counter registered with the client. Increments are accumulated
+// locally and the sum is emitted once per flush interval.
+type RegisteredCounter struct {
+	instrument
+
+	count atomic.Int64
+}
+
+// RegisterCounter returns the counter registered under the key and tags, registering it if needed.
+func (c *Client) RegisterCounter(key string, tags ...Tag) *RegisteredCounter {
+	inst := c.instruments.register(c, "c", "c", key, tags, func() registered {
+		return new(RegisteredCounter)
+	})
+
+	return inst.(*RegisteredCounter)
+}
+
+// Add increases the counter by value.
+func (r *RegisteredCounter) Add(value int64) {
+	r.count.Add(value)
+	r.client.instruments.touch(r)
+}
+
+// Increment increases the counter by 1.
+func (r *RegisteredCounter) Increment() {
+	r.Add(1)
+}
+
+func (r *RegisteredCounter) base() *instrument {
+	return &r.instrument
+}
+
+func (r *RegisteredCounter) collect(c *Client) {
+	if count := r.count.Swap(0); count != 0 {
+		c.send(r.key, "c", intValue(count), r.tags)
+	}
+}
+
+func (r *RegisteredCounter) value() float64 {
+	return float64(r.count.Load())
+}
+
+// RegisteredGauge is a gauge registered with the client. Its last value is emitted once per flush interval.
+type RegisteredGauge struct {
+	instrument
+
+	bits atomic.Uint64
+	set  atomic.Bool
+}
+
+// RegisterGauge returns the gauge registered under the key and tags, registering it if needed.
+func (c *Client) RegisterGauge(key string, tags ...Tag) *RegisteredGauge {
+	inst := c.instruments.register(c, "g", "g", key, tags, func() registered {
+		return new(RegisteredGauge)
+	})
+
+	return inst.(*RegisteredGauge)
+}
+
+// Set sets the gauge to value.
+func (r *RegisteredGauge) Set(value float64) {
+	r.bits.Store(math.Float64bits(value))
+	r.set.Store(true)
+	r.client.instruments.touch(r)
+}
+
+func (r *RegisteredGauge) base() *instrument {
+	return &r.instrument
+}
+
+func (r *RegisteredGauge) collect(c *Client) {
+	if !r.set.Load() {
+		return
+	}
+
+	c.send(r.key, "g", floatValue(math.Float64frombits(r.bits.Load())), r.tags)
+}
+
+func (r *RegisteredGauge) value() float64 {
+	return math.Float64frombits(r.bits.Load())
+}
@@ -0,0 +1,40 @@
+package statsd
+
+import "time"
+
+// Counter sends counters. Libraries can accept it instead of a *Client to require only what they use.
+type Counter interface {
+	Count(key string, value int64, tags ...Tag)
+	Increment(key string, tags ...Tag)
+}
+
+// Gauger sends gauges.
+type Gauger interface {
+	Gauge(key string, value float64, tags ...Tag)
+}
+
+// Timer sends timings.
+type Timer interface {
+	Timing(key string, duration time.Duration, tags ...Tag)
+	Timer(key string, tags ...Tag) func()
+}
+
+// Closer flushes the remaining metrics and releases the resources of a client.
+type Closer interface {
+	Close()
+}
+
+// Statter sends all metric types and can be closed. It is implemented by *Client.
+type Statter interface {
+	Counter
+	Gauger
+	Timer
+	Closer
+}
+
+var (
+	_ Statter = (*Client)(nil)
+	_ Counter = (*Namespace)(nil)
+	_ Gauger  = (*Namespace)(nil)
+	_ Timer   = (*Namespace)(nil)
+)
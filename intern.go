@@ -0,0 +1,62 @@
+package statsd
+
+import "sync"
+
+// maxInternedStrings bounds the number of strings Intern keeps, so tag values built from
+// unbounded input, e.g. user IDs, don't grow the table forever.
+const maxInternedStrings = 4096
+
+// interned maps strings to their canonical copy.
+var interned = struct { //nolint:gochecknoglobals
+	lock    sync.RWMutex
+	strings map[string]string
+}{
+	lock:    sync.RWMutex{},
+	strings: make(map[string]string),
+}
+
+// Intern returns the canonical copy of s, so tag keys and values built at runtime, e.g. by
+// concatenation, are kept once instead of once per queued metric. Once the table is full,
+// new strings are returned as they are.
+func Intern(s string) string {
+	interned.lock.RLock()
+	canonical, ok := interned.strings[s]
+	interned.lock.RUnlock()
+
+	if ok {
+		return canonical
+	}
+
+	return intern(s)
+}
+
+// InternBytes returns the canonical string equal to b. Unlike string(b), it only allocates the first
+// time a value is seen, so tag values formatted into a stack buffer, e.g. with strconv.AppendInt,
+// cost no allocation once interned.
+func InternBytes(b []byte) string {
+	interned.lock.RLock()
+	canonical, ok := interned.strings[string(b)] // Doesn't allocate
+	interned.lock.RUnlock()
+
+	if ok {
+		return canonical
+	}
+
+	return intern(string(b))
+}
+
+// intern adds s to the table unless it's full.
+func intern(s string) string {
+	interned.lock.Lock()
+	defer interned.lock.Unlock()
+
+	if canonical, ok := interned.strings[s]; ok {
+		return canonical
+	}
+
+	if len(interned.strings) < maxInternedStrings {
+		interned.strings[s] = s
+	}
+
+	return s
+}
@@ -0,0 +1,82 @@
+package statsd_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/devem-tech/statsd"
+	"github.com/devem-tech/statsd/statsdtest"
+)
+
+// BenchmarkTagValues sends counters tagged with values formatted at runtime, e.g. shard numbers,
+// converting them to strings on every call or interning them.
+func BenchmarkTagValues(b *testing.B) {
+	cases := []struct {
+		name  string
+		value func(buf []byte) string
+	}{
+		{name: "string", value: func(buf []byte) string { return string(buf) }},
+		{name: "interned", value: statsd.InternBytes},
+	}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			var sink statsdtest.NullSink
+
+			client, flush := newTickedClient(b, &sink)
+
+			var buf [20]byte
+
+			b.ReportAllocs()
+
+			for i := range b.N {
+				shard := c.value(strconv.AppendInt(buf[:0], int64(10_000+i%64), 10))
+				client.Increment("requests", statsd.Tag{Key: "shard", Value: shard})
+
+				if i%1000 == 999 {
+					flush()
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkTagSlices sends metrics with the same tags over and over: passed variadically, which doesn't
+// allocate a backing array per call, and through a delegated namespace, which prefixes the tag keys
+// in pooled scratch space.
+func BenchmarkTagSlices(b *testing.B) {
+	tags := []statsd.Tag{{Key: "region", Value: "eu"}, {Key: "status", Value: "ok"}, {Key: "method", Value: "GET"}}
+
+	b.Run("variadic", func(b *testing.B) {
+		var sink statsdtest.NullSink
+
+		client, flush := newTickedClient(b, &sink)
+
+		b.ReportAllocs()
+
+		for i := range b.N {
+			client.Increment("requests", tags...)
+
+			if i%1000 == 999 {
+				flush()
+			}
+		}
+	})
+
+	b.Run("delegated", func(b *testing.B) {
+		var sink statsdtest.NullSink
+
+		client, flush := newTickedClient(b, &sink)
+		ns := client.Delegate("lib")
+
+		b.ReportAllocs()
+
+		for i := range b.N {
+			ns.Increment("requests", tags...)
+
+			if i%1000 == 999 {
+				flush()
+			}
+		}
+	})
+}
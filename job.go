@@ -0,0 +1,58 @@
+package statsd
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Standard names of the metrics of scheduled jobs, tagged with the job name.
+const (
+	JobDuration    = "job.duration"
+	JobSuccess     = "job.success"
+	JobFailure     = "job.failure"
+	JobLastSuccess = "job.last_success"
+	JobOverlap     = "job.overlap"
+
+	TagJob = "job"
+)
+
+// InstrumentJob wraps a scheduled task, e.g. for a cron library, to report the duration of its runs,
+// successful and failed runs, and the Unix time of the last success as a gauge, tagged with the
+// name. Runs starting while a previous run of the returned function is still going are counted as
+// overlaps, which usually means the job takes longer than its schedule allows. A panicking run counts
+// as failed and the panic is propagated.
+func (c *Client) InstrumentJob(name string, fn func() error) func() error {
+	var (
+		tags    = []Tag{{Key: TagJob, Value: name}}
+		running atomic.Int64
+	)
+
+	return func() error {
+		if running.Add(1) > 1 {
+			c.Increment(JobOverlap, tags...)
+		}
+
+		defer running.Add(-1)
+
+		start := time.Now()
+		failed := true
+
+		defer func() {
+			c.Timing(JobDuration, time.Since(start), tags...)
+
+			if failed {
+				c.Increment(JobFailure, tags...)
+
+				return
+			}
+
+			c.Increment(JobSuccess, tags...)
+			c.Gauge(JobLastSuccess, float64(time.Now().Unix()), tags...)
+		}()
+
+		err := fn()
+		failed = err != nil
+
+		return err
+	}
+}
@@ -0,0 +1,45 @@
+package statsd_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/devem-tech/statsd"
+)
+
+func TestInstrumentJobPanic(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := statsd.New(statsd.Writer(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job := client.InstrumentJob("cleanup", func() error {
+		panic("disk gone")
+	})
+
+	for range 2 {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("the panic wasn't propagated")
+				}
+			}()
+
+			_ = job()
+		}()
+	}
+
+	client.Close()
+
+	// A run that panicked doesn't count as running anymore
+	if strings.Contains(buf.String(), "job.overlap") {
+		t.Errorf("sent %q, want no overlap", buf.String())
+	}
+
+	if got := strings.Count(buf.String(), "job.failure;job=cleanup:1|c"); got != 2 {
+		t.Errorf("sent %q, want 2 failures", buf.String())
+	}
+}
@@ -0,0 +1,70 @@
+package statsd
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// listener counts the connections accepted by the wrapped listener.
+type listener struct {
+	net.Listener
+
+	client *Client
+	key    string
+	tags   []Tag
+	active atomic.Int64
+	gauge  *RegisteredGauge
+}
+
+// conn reports its duration when closed.
+type conn struct {
+	net.Conn
+
+	listener *listener
+	start    time.Time
+	closed   atomic.Bool
+}
+
+// WrapListener returns a listener that reports the connections accepted by l: a counter of accepted
+// connections ("key.accepted"), a gauge of open connections ("key.active") and a timer of connection
+// durations ("key.duration"), each with the tags.
+func (c *Client) WrapListener(l net.Listener, key string, tags ...Tag) net.Listener {
+	return &listener{
+		Listener: l,
+		client:   c,
+		key:      key,
+		tags:     tags,
+		active:   atomic.Int64{},
+		gauge:    c.RegisterGauge(key+".active", tags...),
+	}
+}
+
+// Accept waits for and returns the next connection to the listener.
+func (l *listener) Accept() (net.Conn, error) {
+	nc, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	l.client.Increment(l.key+".accepted", l.tags...)
+	l.gauge.Set(float64(l.active.Add(1)))
+
+	return &conn{
+		Conn:     nc,
+		listener: l,
+		start:    time.Now(),
+		closed:   atomic.Bool{},
+	}, nil
+}
+
+// Close closes the connection. Only the first call is reported.
+func (c *conn) Close() error {
+	if !c.closed.Swap(true) {
+		l := c.listener
+		l.gauge.Set(float64(l.active.Add(-1)))
+		l.client.Timing(l.key+".duration", time.Since(c.start), l.tags...)
+	}
+
+	return c.Conn.Close() //nolint:wrapcheck
+}
@@ -0,0 +1,60 @@
+package statsd
+
+import (
+	"context"
+	"log/slog"
+)
+
+// logger reports operational events of the client, such as reconnects, drops and configuration
+// issues, to an optional structured logger.
+type logger struct {
+	l *slog.Logger
+}
+
+// log writes the message at the level if a logger is configured.
+func (l logger) log(level slog.Level, msg string, args ...any) {
+	if l.l == nil {
+		return
+	}
+
+	l.l.Log(context.Background(), level, "statsd: "+msg, args...)
+}
+
+// validate replaces invalid configuration values with their defaults, logging a warning for each.
+func (o *options) validate() {
+	l := logger{l: o.logger}
+
+	if o.maxBufferSize <= 0 {
+		l.log(slog.LevelWarn, "invalid max buffer size, using the default",
+			slog.Int("max_buffer_size", o.maxBufferSize), slog.Int("default", defaultMaxBufferSize))
+
+		o.maxBufferSize = defaultMaxBufferSize
+	}
+
+	if o.flushInterval <= 0 {
+		l.log(slog.LevelWarn, "invalid flush interval, using the default",
+			slog.Duration("flush_interval", o.flushInterval), slog.Duration("default", defaultFlushInterval))
+
+		o.flushInterval = defaultFlushInterval
+	}
+
+	if o.reservoirSize <= 0 {
+		l.log(slog.LevelWarn, "invalid reservoir size, using the default",
+			slog.Int("reservoir_size", o.reservoirSize), slog.Int("default", defaultReservoirSize))
+
+		o.reservoirSize = defaultReservoirSize
+	}
+
+	if o.separator == "" {
+		l.log(slog.LevelWarn, "empty separator, using the default")
+
+		o.separator = defaultSeparator
+	}
+
+	if o.timingType != TimerType && o.timingType != HistogramType && o.timingType != DistributionType {
+		l.log(slog.LevelWarn, "invalid timing type, using the default",
+			slog.String("timing_type", string(o.timingType)), slog.String("default", string(TimerType)))
+
+		o.timingType = TimerType
+	}
+}
@@ -0,0 +1,36 @@
+package statsd
+
+import "time"
+
+// Time runs fn and sends its duration as a timing under the key, and a "key.success" counter,
+// or a "key.error" counter if fn panics. Panics are propagated.
+func (c *Client) Time(key string, fn func(), tags ...Tag) {
+	_ = c.TimeErr(key, func() error {
+		fn()
+
+		return nil
+	}, tags...)
+}
+
+// TimeErr runs fn and sends its duration as a timing under the key, and a "key.success" counter
+// if fn returned nil, or a "key.error" counter if it returned an error or panicked. It returns
+// the error of fn. Panics are propagated.
+func (c *Client) TimeErr(key string, fn func() error, tags ...Tag) error {
+	start := time.Now()
+	failed := true
+
+	defer func() {
+		c.Timing(key, time.Since(start), tags...)
+
+		if failed {
+			c.Increment(key+".error", tags...)
+		} else {
+			c.Increment(key+".success", tags...)
+		}
+	}()
+
+	err := fn()
+	failed = err != nil
+
+	return err
+}
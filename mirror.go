@@ -0,0 +1,153 @@
+package statsd
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// mirror is a sink receiving a copy of every flush in its own tag format.
+type mirror struct {
+	sink   Sink
+	format TagFormat
+	name   string    // Key of the mirror in SinkStats
+	frame  []byte    // Datagram being written, so datagrams of mirrors never share a buffer
+	stats  sinkStats // Totals of writes to the mirror
+	health health
+}
+
+// encoding holds the lines of a flush in the tag format of one or more mirrors.
+type encoding struct {
+	serializer *serializer // Nil if the mirrors use the tag format of the client
+	mirrors    []*mirror
+	buf        []byte
+}
+
+// mirrorName returns the name of the i-th mirror, counting from 1.
+func mirrorName(sink Sink, i int) string {
+	if s, ok := sink.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return "mirror" + strconv.Itoa(i)
+}
+
+// newEncodings groups the mirrors by tag format.
+func newEncodings(o *options) []*encoding {
+	var encodings []*encoding
+
+	byFormat := make(map[TagFormat]*encoding)
+
+	for i, m := range o.mirrors {
+		e, ok := byFormat[m.format]
+		if !ok {
+			e = &encoding{serializer: nil, mirrors: nil, buf: nil}
+
+			if m.format != o.tagFormat {
+				eo := *o
+				eo.tagFormat = m.format
+				e.serializer = newSerializer(&eo)
+			}
+
+			byFormat[m.format] = e
+			encodings = append(encodings, e)
+		}
+
+		e.mirrors = append(e.mirrors, &mirror{
+			sink:   m.sink,
+			format: m.format,
+			name:   mirrorName(m.sink, i+1),
+			frame:  nil,
+			stats:  sinkStats{},
+			health: newHealth(),
+		})
+	}
+
+	return encodings
+}
+
+// appendLine encodes a metric being flushed. Failures are reported for the tag format of the client only.
+func (e *encoding) appendLine(m metric, tags []Tag) {
+	if e.serializer != nil {
+		e.buf, _ = e.serializer.appendLine(e.buf, m.namespace, m.key, m.value, m.mt, tags)
+	}
+}
+
+// writeMirrors writes the lines of a flush to the mirrors, given the payload in the tag format of the client.
+func (c *Client) writeMirrors(payload []byte) {
+	for _, e := range c.encodings {
+		lines, s := payload, c.serializer
+
+		if e.serializer != nil {
+			s, lines = e.serializer, nil
+
+			if n := len(e.buf); n > 0 {
+				lines = e.buf[:n-1]
+			}
+
+			if c.coalesce && len(lines) > 0 {
+				lines = coalesceCounters(lines)
+			}
+		}
+
+		for _, m := range e.mirrors {
+			c.writeMirror(m, s, lines)
+		}
+
+		e.buf = e.buf[:0]
+	}
+}
+
+// writeMirror splits the lines into datagrams and writes them to the mirror.
+func (c *Client) writeMirror(m *mirror, s *serializer, payload []byte) {
+	size := c.maxBufferSize
+	if c.unbuffered {
+		size = 0
+	}
+
+	var failed []*WriteError
+
+	chunks := 0
+
+	for rest := payload; len(rest) > 0; chunks++ {
+		var datagram []byte
+
+		datagram, rest = splitDatagram(rest, size)
+		lines := datagram
+
+		if s.framed() {
+			m.frame = s.frame(m.frame[:0], datagram)
+			datagram = m.frame
+		}
+
+		if _, err := m.sink.Write(datagram); err != nil {
+			werr := newWriteError(connError(err), lines, c.errorMetrics)
+			werr.Chunk = chunks
+
+			m.stats.failed(werr.Lines)
+			m.health.failure(err)
+
+			failed = append(failed, werr)
+
+			continue
+		}
+
+		m.stats.written(datagram)
+		m.health.success()
+	}
+
+	for _, werr := range failed {
+		werr.Chunks = chunks
+		c.reportError(werr)
+	}
+}
+
+// closeMirrors closes the mirrored sinks.
+func (c *Client) closeMirrors() {
+	for _, e := range c.encodings {
+		for _, m := range e.mirrors {
+			if err := m.sink.Close(); err != nil {
+				c.reportError(connError(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package statsd_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/devem-tech/statsd"
+)
+
+var errSinkDown = errors.New("sink down")
+
+// recordSink is a sink recording its payloads, or failing every write.
+type recordSink struct {
+	failing bool
+	buf     bytes.Buffer
+}
+
+func (s *recordSink) Write(p []byte) (int, error) {
+	if s.failing {
+		return 0, errSinkDown
+	}
+
+	return s.buf.Write(p) //nolint:wrapcheck
+}
+
+func (s *recordSink) Close() error {
+	return nil
+}
+
+// namedSink is a recordSink reporting its name.
+type namedSink struct {
+	recordSink
+
+	name string
+}
+
+func (s *namedSink) String() string {
+	return s.name
+}
+
+func TestSinkStatsPerMirror(t *testing.T) {
+	var primary bytes.Buffer
+
+	telegraf := &namedSink{recordSink: recordSink{failing: false, buf: bytes.Buffer{}}, name: "telegraf"}
+	agent := &namedSink{recordSink: recordSink{failing: true, buf: bytes.Buffer{}}, name: "agent"}
+	nameless := &recordSink{failing: false, buf: bytes.Buffer{}}
+
+	client, err := statsd.New(
+		statsd.Writer(&primary),
+		statsd.MirrorTo(telegraf, statsd.InfluxDBTags),
+		statsd.MirrorTo(agent, statsd.DogStatsDTags),
+		statsd.MirrorTo(nameless, statsd.InfluxDBTags),
+		statsd.ErrorHandler(func(error) {}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.Count("requests", 1, statsd.T("route", "home"))
+	client.Gauge("workers", 4)
+	client.Close()
+
+	stats := client.SinkStats()
+
+	if got := telegraf.buf.String(); got != "requests,route=home:1|c\nworkers:4|g" {
+		t.Errorf("telegraf got %q", got)
+	}
+
+	want := map[string]statsd.SinkStats{
+		"writer":   {Bytes: uint64(primary.Len()), Datagrams: 1, Dropped: 0, Errors: 0},
+		"telegraf": {Bytes: uint64(telegraf.buf.Len()), Datagrams: 1, Dropped: 0, Errors: 0},
+		"agent":    {Bytes: 0, Datagrams: 0, Dropped: 2, Errors: 1},
+		"mirror3":  {Bytes: uint64(nameless.buf.Len()), Datagrams: 1, Dropped: 0, Errors: 0},
+	}
+
+	if len(stats) != len(want) {
+		t.Fatalf("got stats of %v, want %v", stats, want)
+	}
+
+	for name, w := range want {
+		if got := stats[name]; got != w {
+			t.Errorf("%s: got %+v, want %+v", name, got, w)
+		}
+	}
+}
+
+func TestHealthPerMirror(t *testing.T) {
+	var primary bytes.Buffer
+
+	telegraf := &namedSink{recordSink: recordSink{failing: false, buf: bytes.Buffer{}}, name: "telegraf"}
+	agent := &namedSink{recordSink: recordSink{failing: true, buf: bytes.Buffer{}}, name: "agent"}
+
+	client, err := statsd.New(
+		statsd.Writer(&primary),
+		statsd.MirrorTo(telegraf, statsd.InfluxDBTags),
+		statsd.MirrorTo(agent, statsd.DogStatsDTags),
+		statsd.ErrorHandler(func(error) {}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.Increment("requests")
+	client.Close()
+
+	health := client.Health()
+
+	want := map[string]statsd.SinkState{
+		"writer":   statsd.SinkHealthy,
+		"telegraf": statsd.SinkHealthy,
+		"agent":    statsd.SinkFailing,
+	}
+
+	if len(health) != len(want) {
+		t.Fatalf("got health of %v, want %v", health, want)
+	}
+
+	for name, state := range want {
+		if got := health[name].State; got != state {
+			t.Errorf("%s is %v, want %v", name, got, state)
+		}
+	}
+
+	if !errors.Is(health["agent"].LastError, errSinkDown) || health["agent"].ConsecutiveFailures != 1 {
+		t.Errorf("agent health is %+v", health["agent"])
+	}
+}
@@ -0,0 +1,101 @@
+package statsd
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCachedSegments bounds the number of sanitized segments Name keeps, so names built from
+// unbounded input, e.g. user IDs, don't grow the cache forever.
+const maxCachedSegments = 4096
+
+// segmentCache maps name segments with unsafe bytes to their sanitized form.
+var segmentCache = struct { //nolint:gochecknoglobals
+	segments sync.Map
+	size     atomic.Int64
+}{
+	segments: sync.Map{},
+	size:     atomic.Int64{},
+}
+
+// Name joins the segments into a metric name with dots, e.g. Name("api", route, "latency"). Empty
+// segments are skipped, and bytes that would break the name or the line, i.e. unsafe bytes, spaces, dots
+// and the tag separators ';', ',', '=' and '#', are replaced with underscores. Only the returned name is
+// allocated; sanitized segments are cached.
+func Name(segments ...string) string {
+	n := 0
+
+	for _, segment := range segments {
+		if segment != "" {
+			n += len(segment) + 1
+		}
+	}
+
+	if n == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+
+	b.Grow(n - 1)
+
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+
+		b.WriteString(sanitizeSegment(segment))
+	}
+
+	return b.String()
+}
+
+// sanitizeSegment returns the segment with the bytes Name doesn't allow replaced with underscores.
+func sanitizeSegment(segment string) string {
+	if safeSegment(segment) {
+		return segment
+	}
+
+	if sanitized, ok := segmentCache.segments.Load(segment); ok {
+		return sanitized.(string) //nolint:forcetypeassert
+	}
+
+	buf := []byte(segment)
+
+	for i, b := range buf {
+		if !segmentByte(b) {
+			buf[i] = '_'
+		}
+	}
+
+	sanitized := string(buf)
+
+	if segmentCache.size.Load() < maxCachedSegments {
+		if _, loaded := segmentCache.segments.LoadOrStore(segment, sanitized); !loaded {
+			segmentCache.size.Add(1)
+		}
+	}
+
+	return sanitized
+}
+
+// safeSegment reports whether the segment only contains bytes Name allows.
+func safeSegment(segment string) bool {
+	for i := range len(segment) {
+		if !segmentByte(segment[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// segmentByte reports whether b may appear in a segment of a name built by Name.
+func segmentByte(b byte) bool {
+	return !unsafeByte(b) && b != ' ' && b != '.' && b != ';' && b != ',' && b != '=' && b != '#'
+}
@@ -0,0 +1,186 @@
+package statsd
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Namespace is a sibling of a client with its own prefix and default tags, sharing the client's
+// connection, background flusher and buffer. Namespaces are safe for concurrent use.
+type Namespace struct {
+	client    *Client
+	prefix    string
+	tags      []Tag
+	dropped   atomic.Uint64
+	tagPrefix string // Prefix of the tag keys of a delegated namespace
+	sandboxed sandboxedKeys
+}
+
+// namespaces shares the queue budget of a client fairly between the prefixes of its namespaces.
+type namespaces struct {
+	quota  int
+	names  sync.Map     // Prefixes of the namespaces queueing metrics to their *namespaceShare
+	count  atomic.Int64 // Number of names, sharing the quota
+	queued atomic.Int64
+	epoch  atomic.Uint64
+}
+
+// namespaceShare counts the metrics queued under a prefix since the last flush, by all the namespaces
+// with the prefix, so namespaces created again, e.g. per request, share the fair share of their name.
+type namespaceShare struct {
+	queued atomic.Int64
+	epoch  atomic.Uint64 // Flush period of the last metric queued
+}
+
+// newNamespaces returns the accounting for namespaces allowed to queue quota metrics per flush
+// in total. A quota of zero means unlimited.
+func newNamespaces(quota int) *namespaces {
+	return &namespaces{
+		quota:  quota,
+		names:  sync.Map{},
+		count:  atomic.Int64{},
+		queued: atomic.Int64{},
+		epoch:  atomic.Uint64{},
+	}
+}
+
+// admit reports whether the namespace may queue another metric. Once the namespaces queued more
+// metrics than the quota since the last flush, a prefix exceeding its fair share is refused.
+func (n *namespaces) admit(ns *Namespace) bool {
+	if n.quota <= 0 {
+		return true
+	}
+
+	share := n.share(ns.prefix)
+
+	// Reset the share's count on its first metric after a flush
+	if epoch := n.epoch.Load(); share.epoch.Swap(epoch) != epoch {
+		share.queued.Store(0)
+	}
+
+	total := n.queued.Add(1)
+	queued := share.queued.Add(1)
+
+	// The count drops to zero if the share was forgotten by a concurrent reset
+	if total <= int64(n.quota) || queued <= int64(n.quota)/max(n.count.Load(), 1) {
+		return true
+	}
+
+	n.queued.Add(-1)
+	share.queued.Add(-1)
+	ns.dropped.Add(1)
+
+	return false
+}
+
+// share returns the share of the prefix, adding it on the prefix's first metric.
+func (n *namespaces) share(prefix string) *namespaceShare {
+	if v, ok := n.names.Load(prefix); ok {
+		return v.(*namespaceShare) //nolint:forcetypeassert
+	}
+
+	share := &namespaceShare{queued: atomic.Int64{}, epoch: atomic.Uint64{}}
+	share.epoch.Store(n.epoch.Load())
+
+	if v, loaded := n.names.LoadOrStore(prefix, share); loaded {
+		return v.(*namespaceShare) //nolint:forcetypeassert
+	}
+
+	n.count.Add(1)
+
+	return share
+}
+
+// reset starts a new flush period, forgetting the prefixes that queued nothing during the last one,
+// so namespaces named after unbounded values, e.g. tenants, neither grow the names nor shrink
+// the fair share of the others once unused.
+func (n *namespaces) reset() {
+	last := n.epoch.Add(1) - 1
+	n.queued.Store(0)
+
+	n.names.Range(func(prefix, v any) bool {
+		if v.(*namespaceShare).epoch.Load() < last && n.names.CompareAndDelete(prefix, v) { //nolint:forcetypeassert
+			n.count.Add(-1)
+		}
+
+		return true
+	})
+}
+
+// Namespace returns a sibling client whose metric names are prefixed with the name and
+// which adds the tags to its metrics, on top of the client's prefix and default tags.
+func (c *Client) Namespace(name string, tags ...Tag) *Namespace {
+	return c.newNamespace("", nil, name, tags)
+}
+
+// newNamespace returns a namespace nested in the given prefix and tags.
+func (c *Client) newNamespace(prefix string, parentTags []Tag, name string, tags []Tag) *Namespace {
+	prefix = withDot(joinPrefix(strings.TrimSuffix(prefix, "."), name))
+
+	ns := &Namespace{
+		client:    c,
+		prefix:    prefix,
+		tags:      make([]Tag, 0, len(parentTags)+len(tags)),
+		dropped:   atomic.Uint64{},
+		tagPrefix: "",
+		sandboxed: sandboxedKeys{keys: sync.Map{}, size: atomic.Int64{}},
+	}
+
+	ns.tags = append(ns.tags, parentTags...)
+	ns.tags = append(ns.tags, c.stripReserved(tags)...)
+
+	return ns
+}
+
+// Namespace returns a namespace nested in this one.
+func (n *Namespace) Namespace(name string, tags ...Tag) *Namespace {
+	if n.tagPrefix == "" {
+		return n.client.newNamespace(n.prefix, n.tags, name, tags)
+	}
+
+	ns := n.client.newNamespace(n.prefix, n.tags, name, nil)
+	ns.tagPrefix = n.tagPrefix
+	ns.tags = ns.appendSandboxed(ns.tags, tags)
+
+	return ns
+}
+
+// Dropped returns the number of metrics of the namespace dropped for exceeding the fair share of its prefix.
+func (n *Namespace) Dropped() uint64 {
+	return n.dropped.Load()
+}
+
+// Count sends a counter.
+func (n *Namespace) Count(key string, value int64, tags ...Tag) {
+	if value == 0 {
+		return
+	}
+
+	n.client.sendSampled(n, key, "c", intValue(value), tags)
+}
+
+// Increment increases a counter by 1.
+func (n *Namespace) Increment(key string, tags ...Tag) {
+	n.Count(key, 1, tags...)
+}
+
+// Gauge sends a gauge.
+func (n *Namespace) Gauge(key string, value float64, tags ...Tag) {
+	n.client.sendSampled(n, key, "g", floatValue(value), tags)
+}
+
+// Timing sends a timer.
+func (n *Namespace) Timing(key string, duration time.Duration, tags ...Tag) {
+	n.client.sendSampled(n, key, "ms", durationValue(duration), tags)
+}
+
+// Timer starts timing and sends the metric via defer.
+func (n *Namespace) Timer(key string, tags ...Tag) func() {
+	start := time.Now()
+
+	return func() {
+		n.Timing(key, time.Since(start), tags...)
+	}
+}
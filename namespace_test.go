@@ -0,0 +1,133 @@
+package statsd_test
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devem-tech/statsd"
+)
+
+func TestNamespaceQuotaCountsDistinctNames(t *testing.T) {
+	client, err := statsd.New(statsd.Writer(io.Discard), statsd.CallerDriven(), statsd.NamespaceQuota(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// Namespaces created per request share the fair share of their name
+	for range 100 {
+		client.Namespace("requests").Increment("handled")
+	}
+
+	other := client.Namespace("jobs")
+	for range 5 {
+		other.Increment("done")
+	}
+
+	if dropped := other.Dropped(); dropped != 0 {
+		t.Errorf("dropped %d metrics within the fair share", dropped)
+	}
+}
+
+func TestNamespaceSampleRates(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := statsd.New(statsd.Writer(&buf), statsd.SampleRates(map[string]float64{"jobs.*": 0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := client.Namespace("jobs")
+	jobs.Increment("done")
+	jobs.Gauge("queued", 3)
+	jobs.Timing("duration", time.Second)
+	client.Namespace("requests").Increment("handled")
+	client.Close()
+
+	if got, want := buf.String(), "requests.handled:1|c"; got != want {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestNamespaceQuotaCapsNameOverShare(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := statsd.New(statsd.Writer(&buf), statsd.CallerDriven(), statsd.NamespaceQuota(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Namespaces created per request, all over the fair share of their name
+	requests := make([]*statsd.Namespace, 100)
+	for i := range requests {
+		requests[i] = client.Namespace("requests")
+		requests[i].Increment("handled")
+	}
+
+	jobs := client.Namespace("jobs")
+	for range 20 {
+		jobs.Increment("done")
+	}
+
+	client.Close()
+
+	var dropped uint64
+	for _, ns := range requests {
+		dropped += ns.Dropped()
+	}
+
+	// The first 10 metrics are within the quota, then each name keeps its fair share of 5
+	if dropped != 90 || jobs.Dropped() != 15 {
+		t.Errorf("dropped %d requests and %d jobs metrics, want 90 and 15", dropped, jobs.Dropped())
+	}
+
+	if got := strings.Count(buf.String(), "requests.handled:1|c"); got != 10 {
+		t.Errorf("sent %d requests metrics, want 10", got)
+	}
+
+	if got := strings.Count(buf.String(), "jobs.done:1|c"); got != 5 {
+		t.Errorf("sent %d jobs metrics, want 5", got)
+	}
+}
+
+func TestNamespaceQuotaForgetsUnusedNames(t *testing.T) {
+	client, err := statsd.New(statsd.Writer(io.Discard), statsd.CallerDriven(), statsd.NamespaceQuota(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	now := time.Now()
+	client.Tick(now)
+
+	// Namespaces named after tenants, each used once
+	for i := range 100 {
+		client.Namespace("tenant" + strconv.Itoa(i)).Increment("requests")
+	}
+
+	// A flush period without the tenants forgets their names
+	for range 2 {
+		client.Namespace("jobs").Increment("started")
+
+		now = now.Add(time.Hour)
+		client.Tick(now)
+	}
+
+	mail := client.Namespace("mail")
+	for range 10 {
+		mail.Increment("sent")
+	}
+
+	jobs := client.Namespace("jobs")
+	for range 5 {
+		jobs.Increment("done")
+	}
+
+	if dropped := jobs.Dropped(); dropped != 0 {
+		t.Errorf("dropped %d metrics within the fair share of the names in use", dropped)
+	}
+}
@@ -1,7 +1,10 @@
 package statsd
 
 import (
-	"strings"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
 	"time"
 )
 
@@ -30,13 +33,27 @@ func MaxBufferSize(maxBufferSize int) Option {
 }
 
 // FlushInterval sets the time interval between automatic flushes of buffered metrics to StatsD.
+//
+// A flush writes everything queued when it starts, splitting it into as many datagrams as needed,
+// and a client whose queue reaches the max buffer size is flushed without waiting for the interval.
+// As long as a flush takes less than the interval, no metric stays queued for longer than twice
+// the interval; the Watchdog option reports flushes that take longer.
 func FlushInterval(flushInterval time.Duration) Option {
 	return func(o *options) {
 		o.flushInterval = flushInterval
 	}
 }
 
+// FlushPacing sets the delay between the datagrams of a single flush. Spreading a large flush over time
+// instead of bursting it back-to-back prevents drops in the receive buffer of the StatsD server.
+func FlushPacing(delay time.Duration) Option {
+	return func(o *options) {
+		o.pacing = delay
+	}
+}
+
 // ErrorHandler sets a custom error handling function, which is called when there are errors in sending metrics.
+// Panics of the handler are recovered, so they can't stop the background flusher.
 func ErrorHandler(errorHandler func(error)) Option {
 	return func(o *options) {
 		o.errorHandler = errorHandler
@@ -44,10 +61,11 @@ func ErrorHandler(errorHandler func(error)) Option {
 }
 
 // Prefix sets an optional prefix for all metric names to distinguish them or group them logically.
-// A trailing dot is added to the prefix if it does not already exist.
-func Prefix(prefix string) Option {
+// Segments are joined with single dots, and using the option multiple times appends to the prefix,
+// so Prefix("app."), Prefix(".api") results in "app.api." being prepended to every metric name.
+func Prefix(segments ...string) Option {
 	return func(o *options) {
-		o.prefix = strings.TrimSuffix(prefix, ".") + "."
+		o.prefix = joinPrefix(o.prefix, segments...)
 	}
 }
 
@@ -57,3 +75,550 @@ func Tags(tags []Tag) Option {
 		o.tags = tags
 	}
 }
+
+// Writer sets a writer that receives the serialized metrics instead of the UDP connection.
+// Every Write call receives a datagram of whole lines of at most the max buffer size, so a flush results
+// in as many Write calls as datagrams. The writer is not closed when the client is closed.
+func Writer(w io.Writer) Option {
+	return func(o *options) {
+		o.writer = w
+	}
+}
+
+// ReconnectOnRefused enables dialing a new connection when the StatsD server refuses a payload,
+// e.g. because the agent was restarted on another address. The error is reported either way.
+func ReconnectOnRefused(enabled bool) Option {
+	return func(o *options) {
+		o.reconnect = enabled
+	}
+}
+
+// File sets an already connected datagram socket to send metrics through instead of dialing the server,
+// so the process does not need network permissions. The client uses a duplicate of the descriptor,
+// so the file may be closed once New returns.
+func File(f *os.File) Option {
+	return func(o *options) {
+		o.file = f
+	}
+}
+
+// SocketActivation makes the client use a connected datagram socket passed by systemd socket activation.
+// The name selects the socket by its FileDescriptorName; when empty, the first passed socket is used.
+func SocketActivation(name string) Option {
+	return func(o *options) {
+		o.activation = true
+		o.socketName = name
+	}
+}
+
+// CoalesceCounters enables merging identical counter lines within a flush into a single line
+// holding their sum ("x:1|c" × 500 → "x:500|c"), which is cheap and pays off for increment-heavy workloads.
+func CoalesceCounters(enabled bool) Option {
+	return func(o *options) {
+		o.coalesce = enabled
+	}
+}
+
+// InstrumentTTL sets the default number of flush intervals a registered instrument may stay untouched
+// before it stops being emitted and is freed, which keeps the registry bounded when metric names are dynamic.
+// Zero, the default, means that instruments never expire.
+func InstrumentTTL(intervals int) Option {
+	return func(o *options) {
+		o.instrumentTTL = intervals
+	}
+}
+
+// Separator sets the characters separating the lines of a payload, e.g. "\r\n" for collectors that expect them.
+// Defaults to "\n".
+func Separator(separator string) Option {
+	return func(o *options) {
+		o.separator = separator
+	}
+}
+
+// TrailingSeparator makes every payload end with the separator, including after its last line.
+func TrailingSeparator(enabled bool) Option {
+	return func(o *options) {
+		o.trailingSeparator = enabled
+	}
+}
+
+// MaxLineLength sets the maximum length of a single metric line in bytes and the policy applied
+// to longer lines, which would otherwise exceed the datagram size. Rejected lines are reported
+// to the error handler as ErrLineTooLong. Zero, the default, disables the limit.
+func MaxLineLength(length int, policy LinePolicy) Option {
+	return func(o *options) {
+		o.maxLineLength = length
+		o.linePolicy = policy
+	}
+}
+
+// SanitizeNames sets how metric names containing control characters, non-ASCII bytes or
+// the protocol's ':' and '|' are handled. By default names are sent as they are.
+func SanitizeNames(sanitization Sanitization) Option {
+	return func(o *options) {
+		o.sanitization = sanitization
+	}
+}
+
+// TagsFormat sets the syntax used to attach tags to metric lines. Defaults to GraphiteTags.
+func TagsFormat(format TagFormat) Option {
+	return func(o *options) {
+		o.tagFormat = format
+	}
+}
+
+// TimingUnit sets the precision of timings, which are always sent in milliseconds. Units finer than
+// a millisecond result in fractional values, e.g. "1.5|ms" for time.Microsecond. Defaults to time.Millisecond.
+func TimingUnit(unit time.Duration) Option {
+	return func(o *options) {
+		o.timingUnit = unit
+	}
+}
+
+// DropTags omits all tags from metric lines, for legacy backends that accept no tag syntax at all.
+func DropTags() Option {
+	return func(o *options) {
+		o.dropTags = true
+	}
+}
+
+// TagsInName folds the values of the tags with the keys into metric names as ".key.value" segments in
+// the given order, e.g. "requests.region.eu", and omits all other tags, for legacy Graphite backends
+// that accept no tag syntax. Bytes that would break the name are replaced like by Name.
+func TagsInName(keys ...string) Option {
+	return func(o *options) {
+		o.dropTags = true
+		o.nameTags = keys
+	}
+}
+
+// TimingType is the StatsD metric type timings are sent as.
+type TimingType string
+
+const (
+	// TimerType sends timings as StatsD timers: "name:1|ms".
+	TimerType TimingType = "ms"
+	// HistogramType sends timings as DogStatsD histograms: "name:1|h".
+	HistogramType TimingType = "h"
+	// DistributionType sends timings as DogStatsD distributions, aggregated globally by the backend: "name:1|d".
+	DistributionType TimingType = "d"
+)
+
+// TimingsAs sets the metric type Timing, Timer and the other timing methods send, e.g. HistogramType
+// as DataDog recommends, so switching the backend's preferred type needs no code changes.
+// Defaults to TimerType.
+func TimingsAs(mt TimingType) Option {
+	return func(o *options) {
+		o.timingType = mt
+	}
+}
+
+// SourceHost sets the host the metrics originate from, for backends that expect a source dimension
+// distinct from other tags. It is sent as the "host" field in the DogStatsD and InfluxDB tag formats
+// and as "source" in the Graphite one, ahead of the default tags.
+func SourceHost(host string) Option {
+	return func(o *options) {
+		o.sourceHost = host
+	}
+}
+
+// OnClose sets a function called by Close with the lifetime totals of the client,
+// e.g. to log a final summary of the metrics a batch job emitted.
+func OnClose(onClose func(Stats)) Option {
+	return func(o *options) {
+		o.onClose = onClose
+	}
+}
+
+// Retry sets how many times a payload that failed to be written with a transient error
+// (e.g. EAGAIN or ENOBUFS) is written again before it is dropped. The delay before the first
+// retry is backoff and it doubles with every further retry. Retries are disabled by default.
+func Retry(retries int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.retries = retries
+		o.retryBackoff = backoff
+	}
+}
+
+// Pooling enables sharing payload buffers and scratch space between flushes and clients through
+// sync.Pool, so high-throughput clients don't generate steady garbage and idle ones don't hold
+// on to memory. Enabled by default.
+func Pooling(enabled bool) Option {
+	return func(o *options) {
+		o.pooling = enabled
+	}
+}
+
+// ErrorChannel enables the channel returned by Client.Errors, buffering up to size errors.
+// When the channel is full, the oldest errors are dropped.
+func ErrorChannel(size int) Option {
+	return func(o *options) {
+		o.errorChannelSize = size
+	}
+}
+
+// Logger sets a structured logger for operational events of the client, such as reconnects,
+// dropped metrics and configuration issues. It is independent of the error handler.
+func Logger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// Disabled creates the client disabled, so it discards metrics until Client.Enable is called.
+// Feature-flag driven rollouts can switch metrics on and off at runtime without restarting.
+func Disabled(disabled bool) Option {
+	return func(o *options) {
+		o.disabled = disabled
+	}
+}
+
+// ReservoirSize sets how many timings a registered timer keeps per flush interval. When more
+// timings are recorded, a uniform random sample of this size is sent with the matching sample rate.
+// Defaults to 128.
+func ReservoirSize(size int) Option {
+	return func(o *options) {
+		o.reservoirSize = size
+	}
+}
+
+// Watchdog enables detecting a stuck background flusher, e.g. one blocked by a wedged sink.
+// When no flush completes for longer than stall, ErrFlushStalled is reported, and while the flusher
+// stays stuck the oldest queued metrics are dropped whenever the queue exceeds maxQueueSize bytes,
+// so a wedged metrics path can't consume unbounded memory. A maxQueueSize of zero disables dropping.
+// The stall timeout should be well above the flush interval.
+func Watchdog(stall time.Duration, maxQueueSize int) Option {
+	return func(o *options) {
+		o.stallTimeout = stall
+		o.maxQueueSize = maxQueueSize
+	}
+}
+
+// DebugSequence appends a counter line with the key to every datagram, so the number of datagrams
+// that reached the StatsD server can be compared with Stats.Datagrams to quantify packet loss
+// between the client and the agent during network troubleshooting.
+func DebugSequence(key string) Option {
+	return func(o *options) {
+		o.debugSequence = key
+	}
+}
+
+// NamespaceQuota sets how many metrics the namespaces of the client may queue per flush in total
+// before the ones exceeding their fair share (the quota divided by the number of distinct namespace names)
+// are dropped, so one namespace can't starve the others. Namespaces with the same name share its fair share,
+// and names unused for a flush period stop counting. Zero, the default, means unlimited.
+func NamespaceQuota(metrics int) Option {
+	return func(o *options) {
+		o.namespaceQuota = metrics
+	}
+}
+
+// ExtendedTypes enables the KeyValue and Meter methods for servers that accept the "kv" and "m"
+// metric types, like statsite. Without it, these metrics are dropped and ErrUnsupportedType is reported.
+func ExtendedTypes() Option {
+	return func(o *options) {
+		o.extendedTypes = true
+	}
+}
+
+// Unbuffered sends every metric in its own datagram, written before the metric call returns.
+// It trades throughput for immediacy, e.g. in command-line tools or while debugging.
+func Unbuffered() Option {
+	return func(o *options) {
+		o.unbuffered = true
+	}
+}
+
+// BatchID tags every metric of a flush with a random identifier of the flush under the key,
+// so metrics sent more than once, e.g. by retries or replays, can be deduplicated downstream.
+func BatchID(key string) Option {
+	return func(o *options) {
+		o.batchKey = key
+	}
+}
+
+// ErrorMetricNames makes the *WriteError of failed writes name up to n distinct metrics of the
+// lost payload, e.g. "statsd: write: connection refused (12 metrics: api.requests, api.latency)".
+func ErrorMetricNames(n int) Option {
+	return func(o *options) {
+		o.errorMetrics = n
+	}
+}
+
+// SampleRates sets default sample rates for the metrics sent by Count, Increment, Gauge and Timing
+// of the client and its namespaces whose names, including the namespace, match glob patterns,
+// e.g. {"cache.*": 0.01}, so hot metrics are sampled without changing their call sites. The longest
+// matching pattern wins. Metrics sent through Sample use its rate instead, and registered instruments
+// are never sampled.
+func SampleRates(rates map[string]float64) Option {
+	return func(o *options) {
+		o.sampleRates = rates
+	}
+}
+
+// StrictMetrics turns on strict mode: only the defined metrics are sent, with their defined type and
+// tags. Other metrics are dropped and reported as ErrUndefinedMetric, ErrMetricType or ErrTagNotAllowed,
+// catching name typos and type mismatches that would silently fork time series.
+func StrictMetrics(defs ...MetricDefinition) Option {
+	return func(o *options) {
+		o.definitions = append(o.definitions, defs...)
+	}
+}
+
+// ErrorCounter counts the errors reported by the client by their class, e.g. "line_too_long", and sends
+// the counts as a counter with the key, tagged with TagErrorClass, so the health of the metrics pipeline
+// shows up in dashboards. The counts bypass the queue and are sent after every flush.
+func ErrorCounter(key string) Option {
+	return func(o *options) {
+		o.errorCounter = key
+	}
+}
+
+// IsolateCallbacks protects the flusher from user-supplied callbacks. The error handler is called from
+// a separate worker goroutine with a bounded queue, dropping calls when it falls behind, and both error
+// handlers and size gauge callbacks recover from panics. Calls exceeding the budget are logged and
+// counted in Stats.SlowCallbacks. Close waits at most the budget for queued error handler calls.
+func IsolateCallbacks(budget time.Duration) Option {
+	return func(o *options) {
+		o.callbackBudget = budget
+	}
+}
+
+// RandSource sets the source of the random numbers used for sampling decisions: Sample, SampleRates and
+// the reservoirs of registered timers. Use a seeded source to make sampling deterministic in tests, or a
+// faster one on hot paths. The source must be safe for concurrent use. Defaults to the source of the
+// top-level functions of math/rand/v2.
+func RandSource(src rand.Source) Option {
+	return func(o *options) {
+		o.randSource = src
+	}
+}
+
+// globalSource is the source of the top-level functions of math/rand/v2, which is safe for concurrent use.
+type globalSource struct{}
+
+// Uint64 returns a random number from the global source.
+func (globalSource) Uint64() uint64 {
+	return rand.Uint64() //nolint:gosec
+}
+
+// RetainFailedChunks keeps the lines of datagrams that failed to be written, up to maxBytes, and writes
+// them again with the next flush, so a failing datagram of a large flush loses none of its metrics
+// while the others go through. Lines that don't fit are dropped. Retained lines are reported with
+// WriteError.Retained set and are dropped if the client is closed before they are written. Note that
+// a retained gauge may arrive after a newer value of the same gauge.
+func RetainFailedChunks(maxBytes int) Option {
+	return func(o *options) {
+		o.retainLimit = maxBytes
+	}
+}
+
+// PriorityMetrics marks the metrics whose names, namespace included, match glob patterns as high
+// priority, e.g. billing or SLO counters. They are never sampled by SampleRates nor refused by
+// NamespaceQuota, and when the watchdog sheds the queue of a stalled flusher, they are kept as long
+// as they take up at most reserve bytes, while other metrics are dropped first.
+func PriorityMetrics(reserve int, patterns ...string) Option {
+	return func(o *options) {
+		o.priorityReserve = reserve
+		o.priorityPatterns = patterns
+	}
+}
+
+// DebugMetrics sets the initial verbosity level of debug metrics, sent with Debug and the Debug* methods,
+// and their budget: the maximum number of debug metrics queued per flush, 0 for no limit. Debug metrics
+// over budget are discarded. The level can be changed at runtime with SetDebugLevel.
+func DebugMetrics(level, budget int) Option {
+	return func(o *options) {
+		o.debugLevel = level
+		o.debugBudget = budget
+	}
+}
+
+// ReservedTags reserves tag keys, e.g. "env" and "service", for the default tags: per-call and namespace
+// tags with these keys are removed from metrics and reported as ErrReservedTag, so one library can't
+// silently re-label the metrics of another.
+func ReservedTags(keys ...string) Option {
+	return func(o *options) {
+		o.reservedTags = keys
+	}
+}
+
+// CallerDriven runs the client without a background flusher or watchdog goroutine: the application
+// calls Tick from its own event loop instead, e.g. in WASM or plugin environments. Options starting
+// goroutines of their own, i.e. WriteBatching and IsolateCallbacks, should not be combined with it.
+func CallerDriven() Option {
+	return func(o *options) {
+		o.callerDriven = true
+	}
+}
+
+// Output sets a sink that receives the serialized metrics instead of the UDP connection, e.g. an HTTPSink.
+// Unlike a Writer, the sink is closed when the client is closed.
+func Output(sink Sink) Option {
+	return func(o *options) {
+		o.sink = sink
+	}
+}
+
+// StartCounter sends a counter with the key, e.g. "client.start", once when the client is created, tagged
+// with the library version (TagLibraryVersion), the hash of its configuration (TagConfigHash) and the host
+// name (TagHost), so fleet-wide audits can tell which versions and configurations are deployed.
+func StartCounter(key string) Option {
+	return func(o *options) {
+		o.startCounter = key
+	}
+}
+
+// AutoDetect makes the client find the agent instead of dialing the configured host and port.
+// It probes, in order, the Unix socket in DD_DOGSTATSD_SOCKET, port 8125 of DD_AGENT_HOST and
+// localhost:8125, and uses the first that accepts a probe, falling back to localhost:8125.
+// Probing UDP endpoints waits briefly for a refusal, so New may take up to a hundred milliseconds.
+// The agent is detected again when it refuses a payload.
+func AutoDetect() Option {
+	return func(o *options) {
+		o.autoDetect = true
+	}
+}
+
+// BuildInfo adds the tags returned by BuildInfoTags to the default tags of the client,
+// so version tags are always accurate without release scripting.
+func BuildInfo() Option {
+	return func(o *options) {
+		o.tags = append(o.tags[:len(o.tags):len(o.tags)], BuildInfoTags()...)
+	}
+}
+
+// SyncOnClose makes Close wait until the final flush was handed to the operating system, so
+// the last metrics survive e.g. a Kubernetes preStop hook. A Writer is flushed if it has a Flush
+// method and synced if it has a Sync method. A TCP connection passed with File lingers on close
+// until the peer acknowledged the data, for up to the timeout if it is positive. Datagram sockets are unaffected.
+func SyncOnClose(timeout time.Duration) Option {
+	return func(o *options) {
+		o.syncOnClose = true
+		o.syncTimeout = timeout
+	}
+}
+
+// Scheduler makes the client flushed by the shared flusher instead of starting its own.
+func Scheduler(s *FlushScheduler) Option {
+	return withScheduler(s.scheduler)
+}
+
+// MirrorTo makes the client write every flush to the sink as well, with tags in the format, e.g. DogStatsD
+// lines to the agent's Unix socket and InfluxDB lines to Telegraf over UDP. Lines are encoded once per flush
+// and tag format, however many sinks share the format, and split into datagrams per sink. Mirrored sinks are
+// closed with the client; their write failures are reported as *WriteError but neither retried nor retained.
+// SinkStats and Health report the writes to a mirror under the String of its sink, if it implements fmt.Stringer,
+// or "mirror" and its position among the mirrors, e.g. "mirror1".
+func MirrorTo(sink Sink, format TagFormat) Option {
+	return func(o *options) {
+		o.mirrors = append(o.mirrors, &mirror{
+			sink:   sink,
+			format: format,
+			name:   "",
+			frame:  nil,
+			stats:  sinkStats{},
+			health: newHealth(),
+		})
+	}
+}
+
+// TypeSuffixes appends a segment per metric type to metric names, e.g. "requests.count" for counters,
+// matching the naming conventions of other clients during a migration.
+func TypeSuffixes(suffixes TypeNames) Option {
+	return func(o *options) {
+		o.typeSuffixes = suffixes
+	}
+}
+
+// TypePrefixes routes metric types to different prefixes, inserted between the client prefix
+// and the metric name, e.g. "app.counters.requests" for counters.
+func TypePrefixes(prefixes TypeNames) Option {
+	return func(o *options) {
+		o.typePrefixes = prefixes
+	}
+}
+
+// WriteBatching makes the client hold the payloads written to a Writer or Sink for up to delay, e.g. 5ms,
+// and write them together, so frequent small flushes result in fewer writes to a stream without
+// raising the flush interval. Errors of delayed writes are reported to the error handler.
+// Datagram sockets are unaffected, as batching would change the datagrams.
+func WriteBatching(delay time.Duration) Option {
+	return func(o *options) {
+		o.writeBatching = delay
+	}
+}
+
+// AggregateGauges enables keeping only the last value of a gauge per flush, so gauges updated
+// far more often than the flush interval, e.g. per request, cost a single line. Sampled and
+// timestamped gauges, and gauges recorded by a RequestRecorder, are sent as they are, in order
+// with the aggregated ones. Unbuffered clients don't aggregate.
+func AggregateGauges(enabled bool) Option {
+	return func(o *options) {
+		o.aggregateGauges = enabled
+	}
+}
+
+// maxUDPPayloadSize keeps datagrams within a 1500 bytes Ethernet MTU.
+const maxUDPPayloadSize = 1432
+
+// DataDog configures the client for the DataDog agent (DogStatsD): DogStatsD tags,
+// fractional millisecond timings, MTU-sized datagrams and replacement of unsafe bytes in names.
+// Options following it override the preset.
+func DataDog() Option {
+	return func(o *options) {
+		o.tagFormat = DogStatsDTags
+		o.timingUnit = time.Microsecond
+		o.maxBufferSize = maxUDPPayloadSize
+		o.sanitization = SanitizeReplace
+	}
+}
+
+// Telegraf configures the client for the Telegraf StatsD input: InfluxDB style tags,
+// fractional millisecond timings, MTU-sized datagrams and replacement of unsafe bytes in names.
+// Options following it override the preset.
+func Telegraf() Option {
+	return func(o *options) {
+		o.tagFormat = InfluxDBTags
+		o.timingUnit = time.Microsecond
+		o.maxBufferSize = maxUDPPayloadSize
+		o.sanitization = SanitizeReplace
+	}
+}
+
+// GraphiteStatsd configures the client for the StatsD daemon from Etsy with a Graphite backend:
+// Graphite tags, whole millisecond timings, the default datagram size and replacement of unsafe
+// bytes in names. Options following it override the preset.
+func GraphiteStatsd() Option {
+	return func(o *options) {
+		o.tagFormat = GraphiteTags
+		o.timingUnit = time.Millisecond
+		o.maxBufferSize = defaultMaxBufferSize
+		o.sanitization = SanitizeReplace
+	}
+}
+
+// pipeBufferSize is the largest write to a pipe that is atomic (PIPE_BUF), so payloads written
+// to stdout don't interleave with other output.
+const pipeBufferSize = 4096
+
+// Serverless configures the client for serverless metric extensions, e.g. on Lambda or Cloud Run,
+// which read DogStatsD lines from the output of the function instead of UDP: every line is written
+// to w, or to stdout if w is nil, terminated by a newline, with DogStatsD tags, fractional millisecond
+// timings and replacement of unsafe bytes in names. Options following it override the preset.
+func Serverless(w io.Writer) Option {
+	if w == nil {
+		w = os.Stdout
+	}
+
+	return func(o *options) {
+		o.writer = w
+		o.tagFormat = DogStatsDTags
+		o.timingUnit = time.Microsecond
+		o.maxBufferSize = pipeBufferSize
+		o.sanitization = SanitizeReplace
+		o.separator = defaultSeparator
+		o.trailingSeparator = true
+	}
+}
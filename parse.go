@@ -0,0 +1,153 @@
+package statsd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Line is a metric line in its parsed form.
+type Line struct {
+	// Name is the metric name, prefix included.
+	Name string
+	// Value is the value as written, e.g. "1.5".
+	Value string
+	// Type is the StatsD metric type, e.g. "c" or "ms".
+	Type string
+	// Rate is the sample rate, 1 for unsampled metrics.
+	Rate float64
+	// Tags are the tags in the order they appear on the line.
+	Tags []Tag
+	// Timestamp is the Unix time of a DogStatsD "|T" field, 0 if none.
+	Timestamp int64
+}
+
+// ParseLine parses a single metric line in the tag format, e.g. "name;key=value:1|c|@0.5" for GraphiteTags,
+// as the client writes them. Together with Line.Append, it lets custom serializers and servers check that
+// they read and write the same lines as the client.
+func ParseLine(line string, format TagFormat) (Line, error) {
+	l := Line{Name: "", Value: "", Type: "", Rate: 1, Tags: nil, Timestamp: 0}
+
+	head, fields, ok := strings.Cut(line, "|")
+	if !ok {
+		return l, fmt.Errorf("%w: no type: %q", ErrMalformedLine, line)
+	}
+
+	name, value, ok := strings.Cut(head, ":")
+	if !ok || value == "" {
+		return l, fmt.Errorf("%w: no value: %q", ErrMalformedLine, line)
+	}
+
+	var err error
+
+	switch format {
+	case GraphiteTags:
+		name, l.Tags, err = parseNameTags(name, ";")
+	case InfluxDBTags:
+		name, l.Tags, err = parseNameTags(name, ",")
+	case DogStatsDTags:
+	}
+
+	if err != nil {
+		return l, fmt.Errorf("%w: %q", err, line)
+	}
+
+	if name == "" {
+		return l, fmt.Errorf("%w: no name: %q", ErrMalformedLine, line)
+	}
+
+	l.Name, l.Value = name, value
+
+	for i, field := range strings.Split(fields, "|") {
+		switch {
+		case i == 0:
+			l.Type = field
+		case strings.HasPrefix(field, "@"):
+			l.Rate, err = parseNumber(strconv.ParseFloat(field[1:], 64))
+		case strings.HasPrefix(field, "#") && format == DogStatsDTags:
+			l.Tags, err = parseTags(field[1:], ",", ":")
+		case strings.HasPrefix(field, "T") && format == DogStatsDTags:
+			l.Timestamp, err = parseNumber(strconv.ParseInt(field[1:], 10, 64))
+		default:
+			err = fmt.Errorf("%w: unknown field %q", ErrMalformedLine, field)
+		}
+
+		if err != nil {
+			return l, fmt.Errorf("%w: %q", err, line)
+		}
+	}
+
+	if l.Type == "" {
+		return l, fmt.Errorf("%w: no type: %q", ErrMalformedLine, line)
+	}
+
+	return l, nil
+}
+
+// parseNumber wraps the error of parsing a number of a line.
+func parseNumber[N int64 | float64](n N, err error) (N, error) {
+	if err != nil {
+		return n, fmt.Errorf("%w: %w", ErrMalformedLine, err)
+	}
+
+	return n, nil
+}
+
+// parseNameTags splits a name with tags appended after the separator into the name and the tags.
+func parseNameTags(name, separator string) (string, []Tag, error) {
+	name, tags, ok := strings.Cut(name, separator)
+	if !ok {
+		return name, nil, nil
+	}
+
+	parsed, err := parseTags(tags, separator, "=")
+
+	return name, parsed, err
+}
+
+// parseTags parses tags separated by the separator, their keys and values separated by kv.
+func parseTags(tags, separator, kv string) ([]Tag, error) {
+	var parsed []Tag
+
+	for _, tag := range strings.Split(tags, separator) {
+		key, value, ok := strings.Cut(tag, kv)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: malformed tag %q", ErrMalformedLine, tag)
+		}
+
+		parsed = append(parsed, Tag{Key: key, Value: value})
+	}
+
+	return parsed, nil
+}
+
+// Append appends the line serialized in the tag format to buf, without a line terminator, the way
+// the client serializes metrics. The rate is only written if it's between 0 and 1.
+func (l Line) Append(buf []byte, format TagFormat) []byte {
+	buf = append(buf, l.Name...)
+
+	if format != DogStatsDTags {
+		buf = format.appendTags(buf, l.Tags, true)
+	}
+
+	buf = append(buf, ':')
+	buf = append(buf, l.Value...)
+	buf = append(buf, '|')
+	buf = append(buf, l.Type...)
+
+	if l.Rate > 0 && l.Rate < 1 {
+		buf = append(buf, "|@"...)
+		buf = strconv.AppendFloat(buf, l.Rate, 'f', -1, 64)
+	}
+
+	if format == DogStatsDTags {
+		buf = format.appendTags(buf, l.Tags, true)
+
+		if l.Timestamp != 0 {
+			buf = append(buf, "|T"...)
+			buf = strconv.AppendInt(buf, l.Timestamp, 10)
+		}
+	}
+
+	return buf
+}
@@ -0,0 +1,71 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// pingWait is how long Ping waits for an ICMP "port unreachable" reply after writing the probe.
+const pingWait = 50 * time.Millisecond
+
+// Ping verifies that the metrics path is usable, so readiness probes can check it at startup.
+//
+// For UDP an empty datagram is written, which StatsD servers ignore, and Ping waits
+// briefly for the "connection refused" error reported when nothing listens on the port.
+// Flushes wait meanwhile, as the wait sets a deadline on the connection they write to.
+// Clients that write to a custom Writer are always considered reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("statsd: %w", err)
+	}
+
+	c.flushLock.Lock()
+	defer c.flushLock.Unlock()
+
+	conn, ok := c.getConn().(net.Conn)
+	if !ok {
+		return nil
+	}
+
+	return probe(ctx, conn)
+}
+
+// probe writes an empty datagram to the connection and waits briefly for an error reply.
+func probe(ctx context.Context, conn net.Conn) error {
+	deadline := time.Now().Add(pingWait)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("statsd: %w", err)
+	}
+
+	defer conn.SetDeadline(time.Time{}) //nolint:errcheck
+
+	if _, err := conn.Write(nil); err != nil {
+		return connError(err)
+	}
+
+	// StatsD never replies, so the read either times out (the server is
+	// reachable as far as we can tell) or reports an asynchronous error.
+	_, err := conn.Read(make([]byte, 1))
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("statsd: %w", err)
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		return connError(err)
+	}
+
+	return nil
+}
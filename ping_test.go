@@ -0,0 +1,112 @@
+package statsd_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devem-tech/statsd"
+)
+
+// pingConn is a connection whose writes block until released, recording deadlines set meanwhile.
+type pingConn struct {
+	net.Conn
+
+	started chan struct{}
+	release chan struct{}
+
+	lock     sync.Mutex
+	writing  bool
+	overlaps int
+}
+
+func (c *pingConn) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil // The probe
+	}
+
+	c.lock.Lock()
+	c.writing = true
+	c.lock.Unlock()
+
+	c.started <- struct{}{}
+	<-c.release
+
+	c.lock.Lock()
+	c.writing = false
+	c.lock.Unlock()
+
+	return len(p), nil
+}
+
+func (c *pingConn) Read([]byte) (int, error) {
+	return 0, errTimeout{}
+}
+
+func (c *pingConn) SetDeadline(t time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.writing && !t.IsZero() {
+		c.overlaps++
+	}
+
+	return nil
+}
+
+func (c *pingConn) Close() error {
+	return nil
+}
+
+func TestPingDoesNotSetDeadlineDuringFlush(t *testing.T) {
+	conn := &pingConn{
+		Conn:     nil,
+		started:  make(chan struct{}, 1),
+		release:  make(chan struct{}),
+		lock:     sync.Mutex{},
+		writing:  false,
+		overlaps: 0,
+	}
+
+	client, err := statsd.New(statsd.Output(conn), statsd.CallerDriven())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	client.Tick(now)
+	client.Increment("requests")
+
+	flushed := make(chan struct{})
+
+	go func() {
+		defer close(flushed)
+
+		client.Tick(now.Add(time.Hour))
+	}()
+
+	<-conn.started
+
+	pinged := make(chan error)
+
+	go func() {
+		pinged <- client.Ping(context.Background())
+	}()
+
+	// Give Ping the time to probe while the flush is writing
+	time.Sleep(10 * time.Millisecond)
+	close(conn.release)
+	<-flushed
+
+	if err := <-pinged; err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+
+	client.Close()
+
+	if conn.overlaps != 0 {
+		t.Error("Ping set a deadline on the connection while a flush was writing")
+	}
+}
@@ -0,0 +1,57 @@
+package statsd
+
+import "sync"
+
+// maxPooledBufferSize keeps buffers grown by exceptional flushes out of the pools.
+const maxPooledBufferSize = 64 << 10
+
+// bufferPool holds payload buffers shared by all clients, so idle clients don't retain them.
+var bufferPool = sync.Pool{ //nolint:gochecknoglobals
+	New: func() any {
+		b := make([]byte, 0, defaultMaxBufferSize*bufferCapFactor)
+
+		return &b
+	},
+}
+
+// tagsPool holds scratch space for tags.
+var tagsPool = sync.Pool{ //nolint:gochecknoglobals
+	New: func() any {
+		var tags []Tag
+
+		return &tags
+	},
+}
+
+// getBuffer returns an empty payload buffer, taken from the pool if pooling is enabled.
+func (c *Client) getBuffer() *[]byte {
+	if !c.pooling {
+		c.buffer = c.buffer[:0]
+
+		return &c.buffer
+	}
+
+	return bufferPool.Get().(*[]byte)
+}
+
+// putBuffer returns the payload buffer to the pool if pooling is enabled.
+func (c *Client) putBuffer(b *[]byte) {
+	if !c.pooling || cap(*b) > maxPooledBufferSize {
+		return
+	}
+
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
+
+// getTags returns empty scratch space for tags.
+func getTags() *[]Tag {
+	return tagsPool.Get().(*[]Tag)
+}
+
+// putTags returns the scratch space for tags to the pool.
+func putTags(tags *[]Tag) {
+	clear(*tags) // Don't retain the tag strings
+	*tags = (*tags)[:0]
+	tagsPool.Put(tags)
+}
@@ -0,0 +1,79 @@
+package statsd_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/devem-tech/statsd"
+	"github.com/devem-tech/statsd/statsdtest"
+)
+
+// newTickedClient returns a caller-driven client writing to sink, flushed by calling the returned function.
+func newTickedClient(b *testing.B, sink *statsdtest.NullSink, opts ...statsd.Option) (*statsd.Client, func()) {
+	b.Helper()
+
+	opts = append([]statsd.Option{statsd.Writer(sink), statsd.CallerDriven(), statsd.MaxBufferSize(1 << 20)}, opts...)
+
+	client, err := statsd.New(opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Cleanup(client.Close)
+
+	now := time.Now()
+	client.Tick(now)
+
+	return client, func() {
+		now = now.Add(time.Hour)
+		client.Tick(now)
+	}
+}
+
+func BenchmarkPooling(b *testing.B) {
+	for _, pooling := range []bool{true, false} {
+		b.Run("pooling="+strconv.FormatBool(pooling), func(b *testing.B) {
+			var sink statsdtest.NullSink
+
+			client, flush := newTickedClient(b, &sink, statsd.Pooling(pooling))
+			gen := statsdtest.NewGenerator(50, 10, 3)
+
+			b.ReportAllocs()
+
+			for range b.N {
+				gen.EmitN(client, 100)
+				flush()
+			}
+		})
+	}
+}
+
+// BenchmarkPoolingManyClients flushes many clients in turn, as a process with a client per tenant does.
+// Without pooling, every client holds on to payload buffers of its own.
+func BenchmarkPoolingManyClients(b *testing.B) {
+	const clients = 100
+
+	for _, pooling := range []bool{true, false} {
+		b.Run("pooling="+strconv.FormatBool(pooling), func(b *testing.B) {
+			var sink statsdtest.NullSink
+
+			flushes := make([]func(), clients)
+			gens := make([]*statsdtest.Generator, clients)
+			all := make([]*statsd.Client, clients)
+
+			for i := range clients {
+				all[i], flushes[i] = newTickedClient(b, &sink, statsd.Pooling(pooling))
+				gens[i] = statsdtest.NewGenerator(50, 10, 3)
+			}
+
+			b.ReportAllocs()
+
+			for i := range b.N {
+				n := i % clients
+				gens[n].EmitN(all[n], 100)
+				flushes[n]()
+			}
+		})
+	}
+}
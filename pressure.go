@@ -0,0 +1,47 @@
+package statsd
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// pressureHeadroom is the number of full buffers queued that counts as saturation
+// when the queue is unbounded.
+const pressureHeadroom = 16
+
+// pressure tracks the share of metrics dropped during the last flush interval.
+type pressure struct {
+	metrics   uint64 // Totals at the last flush, only accessed by the flusher
+	dropped   uint64
+	dropRatio atomic.Uint64 // Float64 bits
+}
+
+// update records the drop ratio since the previous update from the lifetime totals.
+func (p *pressure) update(metrics, dropped uint64) {
+	ratio := 0.0
+	if metrics > p.metrics {
+		ratio = math.Min(1, float64(dropped-p.dropped)/float64(metrics-p.metrics))
+	}
+
+	p.metrics, p.dropped = metrics, dropped
+	p.dropRatio.Store(math.Float64bits(ratio))
+}
+
+// Pressure returns the saturation of the metrics pipeline between 0 and 1: the larger of the
+// queue fullness, relative to the Watchdog's max queue size if set, and the share of metrics
+// dropped during the last flush interval. Applications can use it to reduce their own metric
+// verbosity, e.g. skip debug metrics, under pressure.
+func (c *Client) Pressure() float64 {
+	limit := c.maxBufferSize * pressureHeadroom
+	if c.watchdog != nil && c.watchdog.limit > 0 {
+		limit = c.watchdog.limit
+	}
+
+	c.queueLock.Lock()
+	size := c.queue.size
+	c.queueLock.Unlock()
+
+	fullness := math.Min(1, float64(size)/float64(limit))
+
+	return math.Max(fullness, math.Float64frombits(c.pressure.dropRatio.Load()))
+}
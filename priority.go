@@ -0,0 +1,62 @@
+package statsd
+
+import (
+	"path"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCachedPriorities bounds the number of metric names whose priority is cached.
+const maxCachedPriorities = 4096
+
+// priorities holds the glob patterns of the names of high-priority metrics.
+type priorities struct {
+	patterns []string
+	reserve  int
+	cache    sync.Map // Metric name to bool
+	size     atomic.Int64
+}
+
+// newPriorities returns the priorities of the patterns with the reserved queue size.
+func newPriorities(reserve int, patterns []string) *priorities {
+	return &priorities{
+		patterns: patterns,
+		reserve:  reserve,
+		cache:    sync.Map{},
+		size:     atomic.Int64{},
+	}
+}
+
+// high reports whether the metric name, including the namespace, matches a priority pattern.
+func (p *priorities) high(ns *Namespace, key string) bool {
+	if len(p.patterns) == 0 {
+		return false
+	}
+
+	name := key
+	if ns != nil {
+		name = ns.prefix + key
+	}
+
+	if cached, ok := p.cache.Load(name); ok {
+		return cached.(bool) //nolint:forcetypeassert
+	}
+
+	high := false
+
+	for _, pattern := range p.patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			high = true
+
+			break
+		}
+	}
+
+	if p.size.Load() < maxCachedPriorities {
+		if _, loaded := p.cache.LoadOrStore(name, high); !loaded {
+			p.size.Add(1)
+		}
+	}
+
+	return high
+}
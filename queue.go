@@ -0,0 +1,205 @@
+package statsd
+
+import (
+	"strconv"
+	"time"
+)
+
+// valueOverhead estimates the bytes a serialized value and metric type add to a line.
+const valueOverhead = 24
+
+// valueKind tells how a queued value is serialized.
+type valueKind uint8
+
+const (
+	intKind valueKind = iota
+	floatKind
+	durationKind
+	stringKind
+)
+
+// value is a metric value kept unformatted until the flusher serializes it.
+// A rate between 0 and 1 means the value was sampled at that rate, and a non-zero
+// timestamp is the Unix time the value was observed at.
+type value struct {
+	kind      valueKind
+	i         int64
+	f         float64
+	s         string
+	rate      float64
+	timestamp int64
+}
+
+// intValue returns an integer value.
+func intValue(v int64) value {
+	return value{kind: intKind, i: v, f: 0, s: "", rate: 1, timestamp: 0}
+}
+
+// floatValue returns a floating-point value.
+func floatValue(v float64) value {
+	return value{kind: floatKind, i: 0, f: v, s: "", rate: 1, timestamp: 0}
+}
+
+// durationValue returns a duration value, serialized in milliseconds.
+func durationValue(d time.Duration) value {
+	return value{kind: durationKind, i: int64(d), f: 0, s: "", rate: 1, timestamp: 0}
+}
+
+// stringValue returns a value serialized as is.
+func stringValue(v string) value {
+	return value{kind: stringKind, i: 0, f: 0, s: v, rate: 1, timestamp: 0}
+}
+
+// at returns the value marked as observed at t.
+func (v value) at(t time.Time) value {
+	v.timestamp = t.Unix()
+
+	return v
+}
+
+// sampled returns the value marked as sampled at the rate.
+func (v value) sampled(rate float64) value {
+	v.rate = rate
+
+	return v
+}
+
+// metric is a metric queued for serialization by the flusher.
+// Its tags are kept in the queue's tag arena between tagsFrom and tagsTo.
+type metric struct {
+	namespace string
+	key       string
+	mt        string
+	value     value
+	tagsFrom  int
+	tagsTo    int
+	priority  bool
+	gauge     *gaugeSlot // Aggregated gauge holding the value, read when flushed
+}
+
+// queue holds the metrics sent since the last flush. Metric calls only append compact
+// structs to it, formatting happens in the flusher goroutine.
+type queue struct {
+	metrics  []metric
+	tags     []Tag
+	size     int // Estimated serialized size in bytes
+	overhead int // Estimated size of a line without its name and tags
+}
+
+// newQueue returns an empty queue for lines that have the given size besides their name and tags.
+func newQueue(overhead int) *queue {
+	return &queue{
+		metrics:  nil,
+		tags:     nil,
+		size:     0,
+		overhead: overhead + valueOverhead,
+	}
+}
+
+// push appends the metric with the tags of its namespace and its own tags to the queue
+// and returns the new estimated size. High-priority metrics are shed last.
+func (q *queue) push(ns *Namespace, key, mt string, v value, tags []Tag, priority bool) int {
+	from := len(q.tags)
+	namespace := ""
+
+	if ns != nil {
+		namespace = ns.prefix
+		q.tags = append(q.tags, ns.tags...)
+		q.size += len(namespace)
+	}
+
+	if len(tags) > 0 {
+		q.tags = append(q.tags, tags...)
+	}
+
+	q.metrics = append(q.metrics, metric{
+		namespace: namespace,
+		key:       key,
+		mt:        mt,
+		value:     v,
+		tagsFrom:  from,
+		tagsTo:    len(q.tags),
+		priority:  priority,
+		gauge:     nil,
+	})
+
+	q.size += len(key) + len(v.s) + q.overhead
+
+	if from == len(q.tags) {
+		return q.size
+	}
+
+	for _, tag := range q.tags[from:] {
+		q.size += len(tag.Key) + len(tag.Value) + 2
+	}
+
+	return q.size
+}
+
+// pushGauge appends the aggregated gauge, whose value is read from the slot when it is flushed,
+// and returns the new estimated size.
+func (q *queue) pushGauge(slot *gaugeSlot) int {
+	size := q.push(slot.ns, slot.key, "g", floatValue(0), slot.tags, slot.priority)
+	q.metrics[len(q.metrics)-1].gauge = slot
+
+	return size
+}
+
+// metricSize returns the estimated serialized size of the queued metric.
+func (q *queue) metricSize(m metric) int {
+	size := len(m.namespace) + len(m.key) + len(m.value.s) + q.overhead
+
+	for _, tag := range q.tags[m.tagsFrom:m.tagsTo] {
+		size += len(tag.Key) + len(tag.Value) + 2
+	}
+
+	return size
+}
+
+// pushQueue appends the metrics of the other queue, which must have the same line overhead,
+// and returns the new estimated size.
+func (q *queue) pushQueue(other *queue) int {
+	offset := len(q.tags)
+	q.tags = append(q.tags, other.tags...)
+
+	for _, m := range other.metrics {
+		m.tagsFrom += offset
+		m.tagsTo += offset
+		q.metrics = append(q.metrics, m)
+	}
+
+	q.size += other.size
+
+	return q.size
+}
+
+// reset empties the queue, keeping its capacity.
+func (q *queue) reset() {
+	clear(q.tags) // Don't retain the tag strings
+	q.metrics = q.metrics[:0]
+	q.tags = q.tags[:0]
+	q.size = 0
+}
+
+// appendValue appends the formatted value to buf. Durations are formatted in milliseconds,
+// with the precision of the timing unit.
+func (s *serializer) appendValue(buf []byte, v value) []byte {
+	switch v.kind {
+	case intKind:
+		return strconv.AppendInt(buf, v.i, 10)
+	case floatKind:
+		return strconv.AppendFloat(buf, v.f, 'f', -1, 64)
+	case durationKind:
+		d := time.Duration(v.i)
+
+		if s.timingUnit >= time.Millisecond {
+			return strconv.AppendInt(buf, d.Milliseconds(), 10)
+		}
+
+		return strconv.AppendFloat(buf, float64(d.Truncate(s.timingUnit))/float64(time.Millisecond), 'f', -1, 64)
+	case stringKind:
+		return append(buf, v.s...)
+	}
+
+	return buf
+}
@@ -0,0 +1,78 @@
+package statsd
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rateWindow is the time constant of the moving average of registered rates.
+const rateWindow = time.Minute
+
+// RegisteredRate tracks the rate of events per second as an exponentially weighted moving average
+// over about a minute and emits it as a gauge once per flush interval, for backends where computing
+// rates from raw counters is awkward.
+type RegisteredRate struct {
+	instrument
+
+	count  atomic.Int64
+	lock   sync.Mutex // Guards the fields below, updated on collection
+	last   time.Time
+	rate   float64
+	primed bool
+}
+
+// RegisterRate returns the rate registered under the key and tags, registering it if needed.
+func (c *Client) RegisterRate(key string, tags ...Tag) *RegisteredRate {
+	inst := c.instruments.register(c, "rate", "rate", key, tags, func() registered {
+		r := new(RegisteredRate)
+		r.last = time.Now()
+
+		return r
+	})
+
+	return inst.(*RegisteredRate)
+}
+
+// Mark records n events.
+func (r *RegisteredRate) Mark(n int64) {
+	r.count.Add(n)
+	r.client.instruments.touch(r)
+}
+
+func (r *RegisteredRate) base() *instrument {
+	return &r.instrument
+}
+
+func (r *RegisteredRate) collect(c *Client) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(r.last)
+
+	if elapsed <= 0 {
+		return
+	}
+
+	r.last = now
+	current := float64(r.count.Swap(0)) / elapsed.Seconds()
+
+	if r.primed {
+		alpha := 1 - math.Exp(-elapsed.Seconds()/rateWindow.Seconds())
+		r.rate += alpha * (current - r.rate)
+	} else {
+		r.rate = current
+		r.primed = true
+	}
+
+	c.send(r.key, "g", floatValue(r.rate), r.tags)
+}
+
+func (r *RegisteredRate) value() float64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.rate
+}
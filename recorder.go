@@ -0,0 +1,109 @@
+package statsd
+
+import "time"
+
+// RequestRecorder accumulates the metrics of a single request, or any other unit of work, and adds
+// them to the client in one locked operation when finished, which reduces lock traffic and keeps the
+// metrics of a request together. A recorder is not safe for concurrent use; it may be reused after Finish.
+// Recorded gauges aren't aggregated by AggregateGauges, but they are sent after the aggregated value of the
+// same gauge sent before Finish, and updates after Finish aggregate after them.
+type RequestRecorder struct {
+	client *Client
+	queue  *queue
+}
+
+// NewRequestRecorder returns an empty recorder for the client.
+func (c *Client) NewRequestRecorder() *RequestRecorder {
+	return &RequestRecorder{
+		client: c,
+		queue:  newQueue(c.serializer.overhead()),
+	}
+}
+
+// Count records a counter.
+func (r *RequestRecorder) Count(key string, value int64, tags ...Tag) {
+	if value == 0 {
+		return
+	}
+
+	r.record(key, "c", intValue(value), tags)
+}
+
+// Increment records a counter increased by 1.
+func (r *RequestRecorder) Increment(key string, tags ...Tag) {
+	r.Count(key, 1, tags...)
+}
+
+// Gauge records a gauge.
+func (r *RequestRecorder) Gauge(key string, value float64, tags ...Tag) {
+	r.record(key, "g", floatValue(value), tags)
+}
+
+// Timing records a timer.
+func (r *RequestRecorder) Timing(key string, duration time.Duration, tags ...Tag) {
+	r.record(key, "ms", durationValue(duration), tags)
+}
+
+// Timer starts timing and records the metric via defer.
+func (r *RequestRecorder) Timer(key string, tags ...Tag) func() {
+	start := time.Now()
+
+	return func() {
+		r.Timing(key, time.Since(start), tags...)
+	}
+}
+
+// Finish adds the recorded metrics to the client and empties the recorder.
+func (r *RequestRecorder) Finish() {
+	defer r.queue.reset()
+
+	if len(r.queue.metrics) == 0 || r.client.disabled.Load() {
+		return
+	}
+
+	r.client.enqueue(len(r.queue.metrics), func(q *queue) int {
+		r.evictGauges()
+
+		return q.pushQueue(r.queue)
+	})
+}
+
+// evictGauges seals the cached values of the recorded gauges, so they stay ahead of the recorded ones.
+func (r *RequestRecorder) evictGauges() {
+	if r.client.gauges == nil {
+		return
+	}
+
+	for _, m := range r.queue.metrics {
+		if m.mt == "g" {
+			r.client.gauges.evict(nil, m.key, r.queue.tags[m.tagsFrom:m.tagsTo])
+		}
+	}
+}
+
+// record adds the metric to the recorder, unless the client is disabled, the metric is dropped,
+// left out of its sample or rejected by strict mode, as if it was sent by the client.
+func (r *RequestRecorder) record(key, mt string, v value, tags []Tag) {
+	c := r.client
+	if c.disabled.Load() || c.filter.Load().drops(nil, key) {
+		return
+	}
+
+	v, ok := c.sample(nil, key, v)
+	if !ok {
+		return
+	}
+
+	tags = c.stripReserved(tags)
+
+	if c.definitions != nil {
+		if err := c.definitions.check(nil, key, mt, tags); err != nil {
+			c.stats.dropped.Add(1)
+			c.reportError(err)
+
+			return
+		}
+	}
+
+	r.queue.push(nil, key, mt, v, tags, c.priorities.high(nil, key))
+}
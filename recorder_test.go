@@ -0,0 +1,63 @@
+package statsd_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/devem-tech/statsd"
+)
+
+func TestRequestRecorderGates(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := statsd.New(
+		statsd.Writer(&buf),
+		statsd.SampleRates(map[string]float64{"sampled.*": 0}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.DropMetrics("dropped.*")
+
+	r := client.NewRequestRecorder()
+	r.Increment("dropped.requests")
+	r.Increment("sampled.requests")
+	r.Increment("requests")
+	r.Finish()
+
+	client.Disable()
+	r.Increment("disabled.requests")
+	client.Enable()
+	r.Finish()
+
+	client.Close()
+
+	if got, want := buf.String(), "requests:1|c"; got != want {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestRequestRecorderGaugesAfterAggregated(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := statsd.New(statsd.Writer(&buf), statsd.AggregateGauges(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.Gauge("pool", 1)
+	client.Gauge("pool", 2)
+
+	r := client.NewRequestRecorder()
+	r.Gauge("pool", 3)
+	r.Finish()
+
+	client.Gauge("pool", 4)
+	client.Gauge("pool", 5)
+	client.Close()
+
+	if got, want := buf.String(), "pool:2|g\npool:3|g\npool:5|g"; got != want {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,104 @@
+package statsd
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Registry manages named clients, e.g. one per tenant or destination, which share a single
+// background flusher instead of running one each. Clients are created on first use.
+type Registry struct {
+	lock      sync.Mutex
+	clients   map[string]*Client
+	configure func(name string) []Option
+	scheduler *scheduler
+	closed    bool
+}
+
+// NewRegistry returns a registry whose clients are flushed every interval and created with
+// the options returned by configure for their name, e.g. a per-tenant prefix or destination.
+// The FlushInterval option has no effect on clients of a registry. A non-positive interval uses
+// the default flush interval. It panics if configure is nil.
+func NewRegistry(interval time.Duration, configure func(name string) []Option) *Registry {
+	if configure == nil {
+		panic("statsd: nil registry configure function")
+	}
+
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+
+	return &Registry{
+		lock:      sync.Mutex{},
+		clients:   make(map[string]*Client),
+		configure: configure,
+		scheduler: newScheduler(interval),
+		closed:    false,
+	}
+}
+
+// Client returns the client with the given name, creating it if it doesn't exist yet.
+// It returns ErrClosed once the registry is closed.
+func (r *Registry) Client(name string) (*Client, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return nil, ErrClosed
+	}
+
+	if c, ok := r.clients[name]; ok {
+		return c, nil
+	}
+
+	opts := append(slices.Clip(r.configure(name)), withScheduler(r.scheduler))
+
+	c, err := New(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	r.clients[name] = c
+
+	return c, nil
+}
+
+// Remove closes the client with the given name and removes it from the registry, e.g. when its
+// tenant is gone, so the registry doesn't keep clients no longer used. Client creates it again.
+func (r *Registry) Remove(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if c, ok := r.clients[name]; ok {
+		c.Close()
+		delete(r.clients, name)
+	}
+}
+
+// Close closes all clients of the registry and stops the background flusher. Closing a closed
+// registry has no effect.
+func (r *Registry) Close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	r.closed = true
+
+	for name, c := range r.clients {
+		c.Close()
+		delete(r.clients, name)
+	}
+
+	r.scheduler.stop()
+}
+
+// withScheduler makes the client flushed by a shared scheduler.
+func withScheduler(s *scheduler) Option {
+	return func(o *options) {
+		o.scheduler = s
+	}
+}
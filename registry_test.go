@@ -0,0 +1,126 @@
+package statsd_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devem-tech/statsd"
+)
+
+// syncBuffer is a buffer safe for a flusher writing and a test reading concurrently.
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buf.Write(p) //nolint:wrapcheck
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buf.String()
+}
+
+func TestNewRegistryDefaultsInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		var buf syncBuffer
+
+		registry := statsd.NewRegistry(interval, func(string) []statsd.Option {
+			return []statsd.Option{statsd.Writer(&buf)}
+		})
+
+		client, err := registry.Client("tenant")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client.Increment("requests")
+
+		deadline := time.Now().Add(time.Second)
+		for buf.String() == "" && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if got, want := buf.String(), "requests:1|c"; got != want {
+			t.Errorf("interval %v: flushed %q, want %q", interval, got, want)
+		}
+
+		registry.Close()
+	}
+}
+
+func TestNewRegistryNilConfigure(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewRegistry(nil) didn't panic")
+		}
+	}()
+
+	statsd.NewRegistry(time.Second, nil)
+}
+
+func TestRegistryClosed(t *testing.T) {
+	var buf syncBuffer
+
+	registry := statsd.NewRegistry(time.Hour, func(string) []statsd.Option {
+		return []statsd.Option{statsd.Writer(&buf)}
+	})
+
+	client, err := registry.Client("tenant")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.Increment("requests")
+	registry.Close()
+	registry.Close()
+
+	// Closing flushed the client
+	if got, want := buf.String(), "requests:1|c"; got != want {
+		t.Errorf("flushed %q, want %q", got, want)
+	}
+
+	if _, err := registry.Client("tenant"); !errors.Is(err, statsd.ErrClosed) {
+		t.Errorf("got %v, want ErrClosed", err)
+	}
+}
+
+func TestRegistryRemove(t *testing.T) {
+	var buf syncBuffer
+
+	registry := statsd.NewRegistry(time.Hour, func(string) []statsd.Option {
+		return []statsd.Option{statsd.Writer(&buf)}
+	})
+	defer registry.Close()
+
+	removed, err := registry.Client("tenant")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed.Increment("requests")
+	registry.Remove("tenant")
+
+	// Removing flushed the client
+	if got, want := buf.String(), "requests:1|c"; got != want {
+		t.Errorf("flushed %q, want %q", got, want)
+	}
+
+	client, err := registry.Client("tenant")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client == removed {
+		t.Error("got the removed client")
+	}
+}
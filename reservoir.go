@@ -0,0 +1,89 @@
+package statsd
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultReservoirSize is the number of timings a registered timer keeps per flush interval by default.
+const defaultReservoirSize = 128
+
+// RegisteredTimer is a timer registered with the client. It keeps a uniform random sample of the
+// timings recorded during a flush interval (reservoir sampling) and emits them annotated with the
+// sample rate, a middle ground between sending every timing and lossy summaries.
+type RegisteredTimer struct {
+	instrument
+
+	lock      sync.Mutex
+	reservoir []time.Duration
+	seen      int
+}
+
+// RegisterTimer returns the timer registered under the key and tags, registering it if needed.
+func (c *Client) RegisterTimer(key string, tags ...Tag) *RegisteredTimer {
+	size := c.instruments.reservoirSize
+
+	inst := c.instruments.register(c, "ms", "ms", key, tags, func() registered {
+		t := new(RegisteredTimer)
+		t.reservoir = make([]time.Duration, 0, size)
+
+		return t
+	})
+
+	return inst.(*RegisteredTimer)
+}
+
+// Record adds a timing to the reservoir.
+func (r *RegisteredTimer) Record(duration time.Duration) {
+	r.lock.Lock()
+
+	r.seen++
+
+	if len(r.reservoir) < cap(r.reservoir) {
+		r.reservoir = append(r.reservoir, duration)
+	} else if i := r.client.rand.IntN(r.seen); i < len(r.reservoir) {
+		r.reservoir[i] = duration
+	}
+
+	r.lock.Unlock()
+
+	r.client.instruments.touch(r)
+}
+
+// Time starts timing and records the duration via defer.
+func (r *RegisteredTimer) Time() func() {
+	start := time.Now()
+
+	return func() {
+		r.Record(time.Since(start))
+	}
+}
+
+func (r *RegisteredTimer) base() *instrument {
+	return &r.instrument
+}
+
+func (r *RegisteredTimer) collect(c *Client) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.seen == 0 {
+		return
+	}
+
+	rate := float64(len(r.reservoir)) / float64(r.seen)
+
+	for _, d := range r.reservoir {
+		c.send(r.key, "ms", durationValue(d).sampled(rate), r.tags)
+	}
+
+	r.reservoir = r.reservoir[:0]
+	r.seen = 0
+}
+
+func (r *RegisteredTimer) value() float64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return float64(r.seen)
+}
@@ -0,0 +1,134 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime/metrics"
+	"strings"
+	"time"
+)
+
+// runtimePrefix prefixes the names of the runtime metrics.
+const runtimePrefix = "runtime"
+
+// runtimeCollector reads runtime/metrics and keeps the histograms of the previous read,
+// since histogram counts are cumulative.
+type runtimeCollector struct {
+	samples    []metrics.Sample
+	previous   map[string][]uint64
+	maxTimings int
+}
+
+// ReportRuntime emits the metrics of the runtime/metrics package every interval, until ctx is done.
+// Scalar metrics are sent as gauges, e.g. "/sched/goroutines:goroutines" as "runtime.sched.goroutines".
+// Histograms in seconds, e.g. scheduler latencies and GC pauses, are sent as timings of the
+// observations made since the previous interval; at most maxTimings timings are sent per histogram
+// and interval, sampled and annotated with their sample rate. Most of these observations are shorter
+// than a millisecond, so set the TimingUnit option accordingly. Run it in its own goroutine:
+//
+//	go client.ReportRuntime(ctx, 10*time.Second, 100)
+//
+// It returns the context's error.
+func (c *Client) ReportRuntime(ctx context.Context, interval time.Duration, maxTimings int) error {
+	descs := metrics.All()
+
+	collector := &runtimeCollector{
+		samples:    make([]metrics.Sample, len(descs)),
+		previous:   make(map[string][]uint64),
+		maxTimings: maxTimings,
+	}
+
+	for i, desc := range descs {
+		collector.samples[i].Name = desc.Name
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		collector.collect(c)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("statsd: %w", ctx.Err())
+		}
+	}
+}
+
+// collect reads the runtime metrics and emits them.
+func (r *runtimeCollector) collect(c *Client) {
+	metrics.Read(r.samples)
+
+	for _, sample := range r.samples {
+		key := runtimeKey(sample.Name)
+
+		switch sample.Value.Kind() {
+		case metrics.KindUint64:
+			c.Gauge(key, float64(sample.Value.Uint64()))
+		case metrics.KindFloat64:
+			c.Gauge(key, sample.Value.Float64())
+		case metrics.KindFloat64Histogram:
+			if strings.HasSuffix(sample.Name, ":seconds") {
+				r.collectHistogram(c, sample.Name, key, sample.Value.Float64Histogram())
+			}
+		case metrics.KindBad:
+		}
+	}
+}
+
+// collectHistogram emits the observations the histogram gained since the previous read as timings
+// of their bucket's bound, sampling them down to the maximum number of timings.
+func (r *runtimeCollector) collectHistogram(c *Client, name, key string, h *metrics.Float64Histogram) {
+	previous, ok := r.previous[name]
+	if !ok || len(previous) != len(h.Counts) {
+		r.previous[name] = append(previous[:0], h.Counts...)
+
+		return // Nothing to compare with yet
+	}
+
+	defer func() {
+		r.previous[name] = append(previous[:0], h.Counts...)
+	}()
+
+	var total uint64
+	for i, count := range h.Counts {
+		total += count - previous[i]
+	}
+
+	if total == 0 {
+		return
+	}
+
+	rate := 1.0
+	if r.maxTimings > 0 && total > uint64(r.maxTimings) {
+		rate = float64(r.maxTimings) / float64(total)
+	}
+
+	for i, count := range h.Counts {
+		n := int(math.Round(float64(count-previous[i]) * rate))
+		if n == 0 {
+			continue
+		}
+
+		// Use the upper bound of the bucket, or the lower one for the last, unbounded bucket
+		bound := h.Buckets[i+1]
+		if math.IsInf(bound, 1) {
+			bound = h.Buckets[i]
+		}
+
+		v := durationValue(time.Duration(bound * float64(time.Second))).sampled(rate)
+
+		for range n {
+			c.send(key, "ms", v, nil)
+		}
+	}
+}
+
+// runtimeKey returns the metric name of a runtime metric, e.g. "runtime.gc.pauses" for "/gc/pauses:seconds".
+func runtimeKey(name string) string {
+	name, _, _ = strings.Cut(name, ":")
+
+	return runtimePrefix + strings.ReplaceAll(name, "/", ".")
+}
@@ -0,0 +1,106 @@
+package statsd
+
+import (
+	"maps"
+	"path"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCachedRates bounds the number of metric names whose sample rate is cached.
+const maxCachedRates = 4096
+
+// sampleRates holds the default sample rates of metric names matching glob patterns.
+type sampleRates struct {
+	patterns map[string]float64
+	cache    sync.Map // Metric name to *float64, nil if no pattern matches
+	size     atomic.Int64
+}
+
+// newSampleRates returns the sample rates for the patterns.
+func newSampleRates(patterns map[string]float64) *sampleRates {
+	return &sampleRates{
+		patterns: patterns,
+		cache:    sync.Map{},
+		size:     atomic.Int64{},
+	}
+}
+
+// rate returns the sample rate of the metric name, including the namespace. When several patterns match,
+// the longest one wins. It reports false if no pattern matches.
+func (s *sampleRates) rate(ns *Namespace, key string) (float64, bool) {
+	if len(s.patterns) == 0 {
+		return 1, false
+	}
+
+	if ns != nil {
+		key = ns.prefix + key
+	}
+
+	if cached, ok := s.cache.Load(key); ok {
+		if rate := cached.(*float64); rate != nil { //nolint:forcetypeassert
+			return *rate, true
+		}
+
+		return 1, false
+	}
+
+	var (
+		rate  *float64
+		match string
+	)
+
+	for pattern, r := range s.patterns {
+		if ok, _ := path.Match(pattern, key); !ok {
+			continue
+		}
+
+		if rate == nil || len(pattern) > len(match) || (len(pattern) == len(match) && pattern < match) {
+			rate, match = &r, pattern
+		}
+	}
+
+	if s.size.Load() < maxCachedRates {
+		if _, loaded := s.cache.LoadOrStore(key, rate); !loaded {
+			s.size.Add(1)
+		}
+	}
+
+	if rate == nil {
+		return 1, false
+	}
+
+	return *rate, true
+}
+
+// sendSampled queues the metric of the namespace, if any, sampling it at the default rate of its name
+// if there is one. Count, Gauge and Timing of clients and namespaces send through it.
+func (c *Client) sendSampled(ns *Namespace, key, mt string, v value, tags []Tag) {
+	if v, ok := c.sample(ns, key, v); ok {
+		c.sendTo(ns, key, mt, v, tags)
+	}
+}
+
+// sample samples the value at the default rate of its name, if there is one. It reports false
+// if the value isn't part of the sample.
+func (c *Client) sample(ns *Namespace, key string, v value) (value, bool) {
+	if rate, ok := c.sampleRates.Load().rate(ns, key); ok && rate < 1 && !c.priorities.high(ns, key) {
+		if c.rand.Float64() >= rate {
+			return v, false
+		}
+
+		v = v.sampled(rate)
+	}
+
+	return v, true
+}
+
+// SetSampleRates replaces the default sample rates set with the SampleRates option at runtime,
+// e.g. to throttle a noisy metric during an incident. Nil restores the configured rates.
+func (c *Client) SetSampleRates(rates map[string]float64) {
+	if rates == nil {
+		rates = c.config.SampleRates
+	}
+
+	c.sampleRates.Store(newSampleRates(maps.Clone(rates)))
+}
@@ -0,0 +1,63 @@
+package statsd
+
+import "time"
+
+// Sample is a sampling decision made once for a logical event, e.g. a request, and applied to all
+// metrics emitted for it, so counters and timers of the event stay consistent with each other.
+// Metrics of a kept sample are sent annotated with the sample rate; the others are skipped.
+type Sample struct {
+	client *Client
+	rate   float64
+	keep   bool
+}
+
+// Sample makes a sampling decision for an event, keeping it with the probability rate.
+// Rates of 1 or more keep every event.
+func (c *Client) Sample(rate float64) Sample {
+	return Sample{
+		client: c,
+		rate:   rate,
+		keep:   rate >= 1 || c.rand.Float64() < rate,
+	}
+}
+
+// Kept reports whether the metrics of the event are sent, so expensive measurements can be skipped.
+func (s Sample) Kept() bool {
+	return s.keep
+}
+
+// Count sends a counter if the event was kept.
+func (s Sample) Count(key string, value int64, tags ...Tag) {
+	if !s.keep || value == 0 {
+		return
+	}
+
+	s.client.send(key, "c", intValue(value).sampled(s.rate), tags)
+}
+
+// Increment increases a counter by 1 if the event was kept.
+func (s Sample) Increment(key string, tags ...Tag) {
+	s.Count(key, 1, tags...)
+}
+
+// Timing sends a timer if the event was kept.
+func (s Sample) Timing(key string, duration time.Duration, tags ...Tag) {
+	if !s.keep {
+		return
+	}
+
+	s.client.send(key, "ms", durationValue(duration).sampled(s.rate), tags)
+}
+
+// Timer starts timing and sends the metric via defer if the event was kept.
+func (s Sample) Timer(key string, tags ...Tag) func() {
+	if !s.keep {
+		return func() {}
+	}
+
+	start := time.Now()
+
+	return func() {
+		s.Timing(key, time.Since(start), tags...)
+	}
+}
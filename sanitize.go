@@ -0,0 +1,61 @@
+package statsd
+
+// Sanitization decides how metric names with unsafe bytes are handled. Unsafe bytes are
+// control characters, non-ASCII bytes (including invalid UTF-8) and the protocol's ':' and '|'.
+type Sanitization int
+
+const (
+	// SanitizeNone sends names as they are.
+	SanitizeNone Sanitization = iota
+	// SanitizePercentEncode replaces every unsafe byte with its percent-encoded form, e.g. "%C3%A9".
+	SanitizePercentEncode
+	// SanitizeReplace replaces every unsafe byte with an underscore.
+	SanitizeReplace
+	// SanitizeReject drops metrics with unsafe names and reports them as ErrInvalidName.
+	SanitizeReject
+)
+
+const upperHex = "0123456789ABCDEF"
+
+// unsafeByte reports whether b must not appear in a sanitized metric name.
+func unsafeByte(b byte) bool {
+	return b < ' ' || b > '~' || b == ':' || b == '|'
+}
+
+// safeName reports whether the name contains no unsafe bytes.
+func safeName(name string) bool {
+	for i := range len(name) {
+		if unsafeByte(name[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// appendName appends the name sanitized according to s to buf.
+// It reports false if the name is rejected, in which case buf is returned unchanged.
+func (s Sanitization) appendName(buf []byte, name string) ([]byte, bool) {
+	if s == SanitizeNone || safeName(name) {
+		return append(buf, name...), true
+	}
+
+	if s == SanitizeReject {
+		return buf, false
+	}
+
+	for i := range len(name) {
+		b := name[i]
+
+		switch {
+		case !unsafeByte(b):
+			buf = append(buf, b)
+		case s == SanitizePercentEncode:
+			buf = append(buf, '%', upperHex[b>>4], upperHex[b&0x0f])
+		default:
+			buf = append(buf, '_')
+		}
+	}
+
+	return buf, true
+}
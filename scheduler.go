@@ -0,0 +1,120 @@
+package statsd
+
+import (
+	"sync"
+	"time"
+)
+
+// scheduler flushes the metrics of its clients in the background, once per interval and
+// whenever a client requests it. A client owns a private scheduler unless it shares one.
+type scheduler struct {
+	lock     sync.Mutex
+	clients  map[*Client]struct{}
+	interval time.Duration
+	wake     chan struct{}
+	quit     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// newScheduler returns a running scheduler flushing its clients every interval.
+func newScheduler(interval time.Duration) *scheduler {
+	s := &scheduler{
+		lock:     sync.Mutex{},
+		clients:  make(map[*Client]struct{}),
+		interval: interval,
+		wake:     make(chan struct{}, 1), // Buffer by 1 to prevent locks
+		quit:     make(chan struct{}),
+		wg:       sync.WaitGroup{},
+	}
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		s.run()
+	}()
+
+	return s
+}
+
+// run is the loop of the background flusher.
+func (s *scheduler) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.loop(ticker.C)
+}
+
+// loop flushes the clients on every tick and whenever a client requests it, until the scheduler stops.
+func (s *scheduler) loop(ticks <-chan time.Time) {
+	for {
+		select {
+		case <-ticks:
+			// Emit the registered instruments and flush all clients
+			s.flush(true)
+		case <-s.wake:
+			// When a client requested flushing, flush it
+			s.flush(false)
+
+			// Don't let requested flushes delay a due tick under sustained load, so queued
+			// metrics are never older than the interval plus the duration of a flush.
+			select {
+			case <-ticks:
+				s.flush(true)
+			default:
+			}
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// flush flushes all clients on a tick, or only the ones that requested it otherwise.
+func (s *scheduler) flush(tick bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for c := range s.clients {
+		if c.flushPending.Swap(false) || tick {
+			if tick {
+				c.instruments.collect(c)
+			}
+
+			c.flushMetrics()
+		}
+	}
+}
+
+// notify wakes the background flusher up.
+func (s *scheduler) notify() {
+	// Do not block if the flush is already
+	// in process (there is already a signal
+	// in the channel).
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// add makes the scheduler flush the client.
+func (s *scheduler) add(c *Client) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.clients[c] = struct{}{}
+}
+
+// remove stops flushing the client, waiting for a flush in progress to finish.
+func (s *scheduler) remove(c *Client) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.clients, c)
+}
+
+// stop stops the background flusher and waits for it to finish.
+func (s *scheduler) stop() {
+	close(s.quit)
+	s.wg.Wait()
+}
@@ -0,0 +1,137 @@
+package statsd
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// writerFunc adapts a function to an io.Writer.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+// newTestClient returns a client writing with write, never flushed on its own interval.
+func newTestClient(t *testing.T, write writerFunc, opts ...Option) *Client {
+	t.Helper()
+
+	client, err := New(append([]Option{Writer(write), FlushInterval(time.Hour)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(client.Close)
+
+	return client
+}
+
+// TestSchedulerTickAfterRequestedFlush keeps a client requesting a flush during every flush, as clients
+// under sustained load do, and checks that a tick due meanwhile is handled right after the flush in
+// progress rather than competing with the next request. Which of two ready channels a select receives
+// from is random, so the scenario is repeated to catch ticks delayed by a requested flush.
+func TestSchedulerTickAfterRequestedFlush(t *testing.T) {
+	for range 32 {
+		testSchedulerTickAfterRequestedFlush(t)
+	}
+}
+
+func testSchedulerTickAfterRequestedFlush(t *testing.T) {
+	t.Helper()
+
+	s := &scheduler{
+		lock:     sync.Mutex{},
+		clients:  make(map[*Client]struct{}),
+		interval: time.Hour,
+		wake:     make(chan struct{}, 1),
+		quit:     make(chan struct{}),
+		wg:       sync.WaitGroup{},
+	}
+
+	var (
+		busy    *Client
+		flushes int
+		delayed int
+	)
+
+	ticks := make(chan time.Time, 1)
+	ticked := make(chan struct{})
+
+	busy = newTestClient(t, func(p []byte) (int, error) {
+		select {
+		case <-ticked:
+			return len(p), nil
+		default:
+		}
+
+		flushes++
+
+		switch {
+		case flushes == 1:
+			ticks <- time.Now()
+		case len(ticks) == 1:
+			delayed++
+		}
+
+		// Request the next flush
+		busy.Increment("busy")
+		busy.flushPending.Store(true)
+		s.notify()
+
+		return len(p), nil
+	})
+
+	// Only flushed on ticks
+	idle := newTestClient(t, func(p []byte) (int, error) {
+		close(ticked)
+
+		return len(p), nil
+	})
+
+	idle.Increment("idle")
+	busy.Increment("busy")
+	busy.flushPending.Store(true)
+
+	s.add(busy)
+	s.add(idle)
+	s.notify()
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		s.loop(ticks)
+	}()
+
+	<-ticked
+	s.stop()
+
+	if delayed != 0 {
+		t.Fatalf("the tick was delayed by %d requested flushes", delayed)
+	}
+}
+
+func TestMaxBufferSizeFlushesWithoutWaiting(t *testing.T) {
+	written := make(chan struct{}, 1)
+
+	client := newTestClient(t, func(p []byte) (int, error) {
+		select {
+		case written <- struct{}{}:
+		default:
+		}
+
+		return len(p), nil
+	}, MaxBufferSize(256))
+
+	// Well over the max buffer size
+	for range 100 {
+		client.Increment("requests")
+	}
+
+	select {
+	case <-written:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the full buffer wasn't flushed before the interval")
+	}
+}
@@ -0,0 +1,108 @@
+package statsd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// schema describes the metrics of a client for documentation and dashboard tooling.
+type schema struct {
+	Prefix  string         `json:"prefix,omitempty"`
+	Metrics []schemaMetric `json:"metrics"`
+}
+
+// schemaMetric describes a metric of the schema.
+type schemaMetric struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Unit        string   `json:"unit,omitempty"`
+}
+
+// ExportSchema returns a JSON description of the metrics known to the client: the metrics defined
+// for strict mode and the registered instruments, with their name (without the prefix), type, tag
+// keys, description and unit, e.g. to generate documentation or dashboards as code.
+func (c *Client) ExportSchema() ([]byte, error) {
+	s := schema{Prefix: c.Prefix(), Metrics: nil}
+
+	for _, def := range c.definitions {
+		s.add(schemaMetric{
+			Name:        def.Key,
+			Type:        emittedType(def.Type, c.serializer.timingType),
+			Tags:        slices.Clone(def.Tags),
+			Description: def.Description,
+			Unit:        def.Unit,
+		})
+	}
+
+	c.instruments.lock.Lock()
+
+	for _, inst := range c.instruments.instruments {
+		b := inst.base()
+
+		tags := make([]string, 0, len(b.tags)+1)
+		for tag := range allTags(b.tags) {
+			tags = append(tags, tag.Key)
+		}
+
+		if t, ok := inst.(*RegisteredTopK); ok {
+			tags = append(tags, t.label)
+		}
+
+		s.add(schemaMetric{
+			Name:        b.key,
+			Type:        emittedType(b.mt, c.serializer.timingType),
+			Tags:        tags,
+			Description: b.description,
+			Unit:        b.unit,
+		})
+	}
+
+	c.instruments.lock.Unlock()
+
+	slices.SortFunc(s.Metrics, func(a, b schemaMetric) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Type, b.Type))
+	})
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+
+	return data, nil
+}
+
+// add adds the metric to the schema, merging it with a metric of the same name and type.
+func (s *schema) add(m schemaMetric) {
+	i := slices.IndexFunc(s.Metrics, func(other schemaMetric) bool {
+		return other.Name == m.Name && other.Type == m.Type
+	})
+
+	if i < 0 {
+		slices.Sort(m.Tags)
+		s.Metrics = append(s.Metrics, m)
+
+		return
+	}
+
+	existing := &s.Metrics[i]
+	existing.Tags = slices.Compact(slices.Sorted(slices.Values(append(existing.Tags, m.Tags...))))
+
+	existing.Description = cmp.Or(existing.Description, m.Description)
+	existing.Unit = cmp.Or(existing.Unit, m.Unit)
+}
+
+// emittedType returns the StatsD type sent for metrics and instruments registered with the type.
+func emittedType(mt string, timing TimingType) string {
+	switch mt {
+	case "ms":
+		return string(timing)
+	case "topk", "rate", "size":
+		return "g"
+	}
+
+	return mt
+}
@@ -0,0 +1,57 @@
+package statsd
+
+import (
+	"fmt"
+	"net"
+)
+
+// Metric is a metric sent by Send.
+type Metric struct {
+	Key string
+	// Type is the StatsD metric type: "c" for counters, "g" for gauges or "ms" for timings.
+	Type string
+	// Value is the counter increment, the gauge value or the timing in milliseconds.
+	Value float64
+	Tags  []Tag
+}
+
+// Send dials the StatsD server at addr, writes the metrics in a single datagram and closes
+// the connection. It suits short-lived programs like cron jobs or init containers, which don't
+// need a buffered client with a background flusher. Metrics are serialized with the default options.
+func Send(addr string, metrics ...Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	s := newSerializer(newOptions())
+
+	var (
+		buf []byte
+		err error
+	)
+
+	for _, m := range metrics {
+		buf, err = s.appendLine(buf, "", m.Key, floatValue(m.Value), m.Type, m.Tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return fmt.Errorf("statsd: %w", err)
+	}
+
+	_, err = conn.Write(buf[:len(buf)-1])
+	if err != nil {
+		conn.Close() //nolint:errcheck
+
+		return connError(err)
+	}
+
+	if err := conn.Close(); err != nil {
+		return fmt.Errorf("statsd: %w", err)
+	}
+
+	return nil
+}
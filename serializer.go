@@ -0,0 +1,355 @@
+package statsd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSeparator separates the lines of a payload.
+const defaultSeparator = "\n"
+
+// serializer encodes metrics into lines and frames the lines of a datagram into a payload.
+// Buffered lines are always terminated by '\n'; the configured separator is applied when framing.
+type serializer struct {
+	prefix        []byte
+	defaultTags   []Tag
+	tags          []byte
+	separator     []byte
+	trailing      bool
+	maxLineLength int
+	linePolicy    LinePolicy
+	sanitization  Sanitization
+	tagFormat     TagFormat
+	dropTags      bool
+	nameTags      []string
+	timingUnit    time.Duration
+	timingType    TimingType
+	pooling       bool
+	typePrefixes  TypeNames
+	typeSuffixes  TypeNames
+}
+
+// TagFormat is the syntax used to attach tags to metric lines.
+type TagFormat int
+
+const (
+	// GraphiteTags appends tags to the metric name: "name;key=value:1|c".
+	GraphiteTags TagFormat = iota
+	// InfluxDBTags appends tags to the metric name as Telegraf expects: "name,key=value:1|c".
+	InfluxDBTags
+	// DogStatsDTags appends tags to the end of the line: "name:1|c|#key:value".
+	DogStatsDTags
+)
+
+// LinePolicy decides what happens to lines longer than the maximum line length.
+type LinePolicy int
+
+const (
+	// TruncateLine drops tags, the per-call ones first, until the line fits. The metric name is never
+	// shortened, so lines that don't fit even without tags are rejected.
+	TruncateLine LinePolicy = iota
+	// RejectLine drops the line.
+	RejectLine
+)
+
+// newSerializer returns a serializer for the client configuration.
+func newSerializer(o *options) *serializer {
+	defaultTags := o.tags
+	if o.sourceHost != "" {
+		defaultTags = append([]Tag{{Key: o.tagFormat.sourceKey(), Value: o.sourceHost}}, o.tags...)
+	}
+
+	tags := o.tagFormat.appendTags(nil, defaultTags, true)
+	if o.dropTags {
+		tags = nil
+	}
+
+	return &serializer{
+		prefix:        []byte(withDot(o.prefix)),
+		defaultTags:   defaultTags,
+		tags:          tags,
+		separator:     []byte(o.separator),
+		trailing:      o.trailingSeparator,
+		maxLineLength: o.maxLineLength,
+		linePolicy:    o.linePolicy,
+		sanitization:  o.sanitization,
+		tagFormat:     o.tagFormat,
+		dropTags:      o.dropTags,
+		nameTags:      o.nameTags,
+		timingUnit:    o.timingUnit,
+		timingType:    o.timingType,
+		pooling:       o.pooling,
+		typePrefixes:  o.typePrefixes,
+		typeSuffixes:  o.typeSuffixes,
+	}
+}
+
+// appendLine appends a single metric line terminated by '\n' to buf, naming it by the namespace,
+// if any, and the key. Lines exceeding the maximum line length are handled according to the line
+// policy; buf is returned unchanged with an error if rejected.
+func (s *serializer) appendLine(buf []byte, namespace, key string, v value, mt string, tags []Tag) ([]byte, error) {
+	if s.sanitization == SanitizeReject && !safeName(key) {
+		return buf, fmt.Errorf("%w: %q", ErrInvalidName, key)
+	}
+
+	start := len(buf)
+	buf = s.encode(buf, namespace, key, v, mt, s.tags, tags)
+
+	if s.maxLineLength <= 0 || len(buf)-start-1 <= s.maxLineLength {
+		return buf, nil
+	}
+
+	if s.linePolicy == TruncateLine && s.truncate(&buf, start, namespace, key, v, mt, tags) {
+		return buf, nil
+	}
+
+	return buf[:start], fmt.Errorf("%w: %s%s%s", ErrLineTooLong, s.prefix, namespace, key)
+}
+
+// truncate replaces the line starting at start in buf with one that fits the maximum line length,
+// dropping tags from the end. It reports false if the line doesn't fit even without tags.
+func (s *serializer) truncate(buf *[]byte, start int, namespace, key string, v value, mt string, tags []Tag) bool {
+	var all *[]Tag
+
+	if s.pooling {
+		all = getTags()
+		defer putTags(all)
+	} else {
+		all = new([]Tag)
+	}
+
+	*all = append(*all, s.defaultTags...)
+	*all = append(*all, tags...)
+
+	for n := len(*all) - 1; n >= 0; n-- {
+		*buf = s.encode((*buf)[:start], namespace, key, v, mt, nil, (*all)[:n])
+
+		if len(*buf)-start-1 <= s.maxLineLength {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encode appends a metric line with the serialized default tags and the tags to buf.
+func (s *serializer) encode(
+	buf []byte, namespace, key string, v value, mt string, defaultTags []byte, tags []Tag,
+) []byte {
+	buf = append(buf, s.prefix...)
+	buf = append(buf, namespace...)
+
+	if p := s.typePrefixes.forType(mt); p != "" {
+		buf = append(buf, p...)
+		buf = append(buf, '.')
+	}
+
+	buf, _ = s.sanitization.appendName(buf, key)
+
+	if len(s.nameTags) > 0 {
+		buf = s.appendNameTags(buf, tags)
+	}
+
+	if suffix := s.typeSuffixes.forType(mt); suffix != "" {
+		buf = append(buf, '.')
+		buf = append(buf, suffix...)
+	}
+
+	if s.tagFormat != DogStatsDTags && !s.dropTags {
+		buf = s.appendAllTags(buf, defaultTags, tags)
+	}
+
+	buf = append(buf, ':')
+	buf = s.appendValue(buf, v)
+	buf = append(buf, '|')
+
+	if mt == "ms" {
+		buf = append(buf, s.timingType...)
+	} else {
+		buf = append(buf, mt...)
+	}
+
+	if v.rate > 0 && v.rate < 1 {
+		buf = append(buf, "|@"...)
+		buf = strconv.AppendFloat(buf, v.rate, 'f', -1, 64)
+	}
+
+	if s.tagFormat == DogStatsDTags {
+		if !s.dropTags {
+			buf = s.appendAllTags(buf, defaultTags, tags)
+		}
+
+		if v.timestamp != 0 {
+			buf = append(buf, "|T"...)
+			buf = strconv.AppendInt(buf, v.timestamp, 10)
+		}
+	}
+
+	buf = append(buf, '\n')
+
+	return buf
+}
+
+// appendNameTags appends the tags folded into the metric name as ".key.value" segments to buf, in the
+// order of their keys. Per-call tags take precedence over default tags with the same key.
+func (s *serializer) appendNameTags(buf []byte, tags []Tag) []byte {
+	for _, key := range s.nameTags {
+		value, ok := lookupTag(tags, key)
+		if !ok {
+			value, ok = lookupTag(s.defaultTags, key)
+		}
+
+		if !ok {
+			continue
+		}
+
+		buf = append(buf, '.')
+		buf = append(buf, sanitizeSegment(key)...)
+		buf = append(buf, '.')
+		buf = append(buf, sanitizeSegment(value)...)
+	}
+
+	return buf
+}
+
+// lookupTag returns the value of the last tag with the key.
+func lookupTag(tags []Tag, key string) (string, bool) {
+	value, found := "", false
+
+	for tag := range allTags(tags) {
+		if tag.Key == key {
+			value, found = tag.Value, true
+		}
+	}
+
+	return value, found
+}
+
+// appendAllTags appends the serialized default tags and the tags to buf.
+func (s *serializer) appendAllTags(buf, defaultTags []byte, tags []Tag) []byte {
+	buf = append(buf, defaultTags...)
+
+	// Most metrics have no per-call tags
+	if len(tags) == 0 {
+		return buf
+	}
+
+	return s.tagFormat.appendTags(buf, tags, len(defaultTags) == 0)
+}
+
+// framed reports whether payloads differ from the buffered lines and need to go through frame.
+func (s *serializer) framed() bool {
+	return s.trailing || string(s.separator) != defaultSeparator
+}
+
+// frame appends the lines of a datagram, given without the final '\n', to dst as a payload
+// using the configured separator and trailing behavior.
+func (s *serializer) frame(dst, lines []byte) []byte {
+	start := 0
+
+	for i, b := range lines {
+		if b != '\n' {
+			continue
+		}
+
+		dst = append(dst, lines[start:i]...)
+		dst = append(dst, s.separator...)
+		start = i + 1
+	}
+
+	dst = append(dst, lines[start:]...)
+
+	if s.trailing {
+		dst = append(dst, s.separator...)
+	}
+
+	return dst
+}
+
+// joinPrefix appends the segments to the prefix, joining them with single dots.
+// Empty segments and dots surrounding the segments are ignored.
+func joinPrefix(prefix string, segments ...string) string {
+	for _, segment := range segments {
+		segment = strings.Trim(segment, ".")
+		if segment == "" {
+			continue
+		}
+
+		if prefix != "" {
+			prefix += "."
+		}
+
+		prefix += segment
+	}
+
+	return prefix
+}
+
+// withDot returns the prefix followed by a dot, or an empty string if the prefix is empty.
+func withDot(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+
+	return prefix + "."
+}
+
+// sourceKey returns the field name the format uses for the source host.
+func (f TagFormat) sourceKey() string {
+	if f == GraphiteTags {
+		return "source"
+	}
+
+	return "host"
+}
+
+// appendTags appends the tags serialized in the format to buf.
+// The first flag tells whether no tags precede these on the line.
+func (f TagFormat) appendTags(buf []byte, tags []Tag, first bool) []byte {
+	for _, tag := range tags {
+		set := tag.Key == tagSetKey
+		if set && tag.Value == "" {
+			continue
+		}
+
+		switch f {
+		case GraphiteTags:
+			buf = append(buf, ';')
+		case InfluxDBTags:
+			buf = append(buf, ',')
+		case DogStatsDTags:
+			if first {
+				buf = append(buf, "|#"...)
+			} else {
+				buf = append(buf, ',')
+			}
+		}
+
+		first = false
+
+		if set {
+			buf = append(buf, tagSetSection(tag.Value, 1+int(f))...)
+
+			continue
+		}
+
+		buf = append(buf, tag.Key...)
+
+		if f == DogStatsDTags {
+			buf = append(buf, ':')
+		} else {
+			buf = append(buf, '=')
+		}
+
+		buf = append(buf, tag.Value...)
+	}
+
+	return buf
+}
+
+// overhead returns the bytes the prefix and the default tags add to every line.
+func (s *serializer) overhead() int {
+	return len(s.prefix) + len(s.tags)
+}
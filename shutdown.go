@@ -0,0 +1,58 @@
+package statsd
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+)
+
+// inflight counts the lines handed to the connection that weren't written or dropped yet,
+// including retained lines.
+type inflight struct {
+	lines atomic.Int64
+	bytes atomic.Int64
+}
+
+// add counts the lines of a payload about to be written.
+func (f *inflight) add(payload []byte) {
+	f.lines.Add(int64(bytes.Count(payload, []byte{'\n'}) + 1))
+	f.bytes.Add(int64(len(payload)))
+}
+
+// done uncounts the lines of a datagram that was written or dropped.
+func (f *inflight) done(lines []byte) {
+	f.lines.Add(-int64(bytes.Count(lines, []byte{'\n'}) + 1))
+	f.bytes.Add(-int64(len(lines)))
+}
+
+// Shutdown closes the client like Close, but returns an *UnsentError with the numbers of metrics and
+// bytes that weren't sent yet if ctx is done first, e.g. because a wedged sink blocks the final flush,
+// for data loss accounting. Closing then continues in the background.
+func (c *Client) Shutdown(ctx context.Context) error {
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+
+		c.Close()
+	}()
+
+	select {
+	case <-closed:
+		return nil
+	case <-ctx.Done():
+	}
+
+	metrics, size := c.unsent()
+
+	return &UnsentError{Err: ctx.Err(), Metrics: metrics, Bytes: size}
+}
+
+// unsent returns the number of metrics that weren't sent yet and their estimated size.
+func (c *Client) unsent() (int, int) {
+	c.queueLock.Lock()
+	metrics, size := len(c.queue.metrics), c.queue.size
+	c.queueLock.Unlock()
+
+	return metrics + int(c.inflight.lines.Load()), size + int(c.inflight.bytes.Load())
+}
@@ -0,0 +1,145 @@
+package statsd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// httpSinkTimeout is the time limit of a single POST request of an HTTPSink.
+const httpSinkTimeout = 5 * time.Second
+
+// errHTTPStatus is returned when an endpoint doesn't accept a payload.
+var errHTTPStatus = errors.New("statsd: unexpected HTTP status")
+
+// Sink receives the serialized payloads of a client, one Write per datagram, and is closed with the client.
+// It replaces the UDP connection, e.g. where sockets aren't available.
+type Sink interface {
+	io.WriteCloser
+}
+
+// HTTPSink is a sink posting every payload as a plain text request body to an endpoint, for environments
+// without sockets, e.g. WASM, or with outbound HTTP only, like proxies accepting StatsD over HTTP.
+// Combined with the WriteBatching option, a request body holds the payloads of many flushes.
+// It is safe for concurrent use.
+type HTTPSink struct {
+	client   *http.Client
+	endpoint string
+	header   http.Header
+	gzip     bool
+}
+
+var _ Sink = (*HTTPSink)(nil)
+
+// HTTPSinkOption configures an HTTPSink.
+type HTTPSinkOption func(*HTTPSink)
+
+// NewHTTPSink returns a sink posting payloads to the endpoint, e.g. "https://statsd-proxy.internal/lines".
+func NewHTTPSink(endpoint string, opts ...HTTPSinkOption) *HTTPSink {
+	s := &HTTPSink{
+		client: &http.Client{
+			Transport:     nil,
+			CheckRedirect: nil,
+			Jar:           nil,
+			Timeout:       httpSinkTimeout,
+		},
+		endpoint: endpoint,
+		header:   http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+		gzip:     false,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// HTTPClient sets the HTTP client sending the requests, e.g. one with a custom TLS configuration.
+func HTTPClient(client *http.Client) HTTPSinkOption {
+	return func(s *HTTPSink) {
+		s.client = client
+	}
+}
+
+// HTTPHeader sets a header sent with every request, e.g. an API key.
+func HTTPHeader(key, value string) HTTPSinkOption {
+	return func(s *HTTPSink) {
+		s.header.Set(key, value)
+	}
+}
+
+// HTTPBearerToken authenticates the requests with the token in the Authorization header.
+func HTTPBearerToken(token string) HTTPSinkOption {
+	return HTTPHeader("Authorization", "Bearer "+token)
+}
+
+// HTTPGzip compresses the request bodies with gzip.
+func HTTPGzip() HTTPSinkOption {
+	return func(s *HTTPSink) {
+		s.gzip = true
+		s.header.Set("Content-Encoding", "gzip")
+	}
+}
+
+// Write posts the payload, failing unless the endpoint responds with a 2xx status.
+func (s *HTTPSink) Write(p []byte) (int, error) {
+	body := p
+
+	if s.gzip {
+		var err error
+		if body, err = compress(p); err != nil {
+			return 0, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("statsd: %w", err)
+	}
+
+	req.Header = s.header.Clone()
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("statsd: %w", err)
+	}
+
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck
+	resp.Body.Close()              //nolint:errcheck
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return 0, fmt.Errorf("%w: %s from %s", errHTTPStatus, resp.Status, s.endpoint)
+	}
+
+	return len(p), nil
+}
+
+// Close releases the idle connections of the sink.
+func (s *HTTPSink) Close() error {
+	s.client.CloseIdleConnections()
+
+	return nil
+}
+
+// compress returns the payload compressed with gzip.
+func compress(p []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+
+	if _, err := w.Write(p); err != nil {
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,47 @@
+package statsd
+
+// RegisteredSizeGauge is a gauge whose value is read from a callback once per flush interval,
+// e.g. the number of items of a cache or a pool. It never expires.
+type RegisteredSizeGauge struct {
+	instrument
+
+	size func() int
+}
+
+// RegisterSizeGauge registers a gauge reporting the value returned by size, e.g.
+// RegisterSizeGauge("cache.items", cache.Len). All callbacks are called in a single pass per flush
+// interval, from the background flusher, and must not register instruments themselves. If a gauge
+// is already registered under the key and tags, it is returned with its callback unchanged.
+func (c *Client) RegisterSizeGauge(key string, size func() int, tags ...Tag) *RegisteredSizeGauge {
+	inst := c.instruments.register(c, "size", "size", key, tags, func() registered {
+		g := new(RegisteredSizeGauge)
+		g.size = size
+
+		return g
+	})
+
+	g := inst.(*RegisteredSizeGauge)
+	g.SetTTL(0)
+
+	return g
+}
+
+func (r *RegisteredSizeGauge) base() *instrument {
+	return &r.instrument
+}
+
+func (r *RegisteredSizeGauge) collect(c *Client) {
+	var size int
+
+	if c.callbacks == nil {
+		size = r.size()
+	} else if !c.callbacks.call("size gauge", r.key, func() { size = r.size() }) {
+		return
+	}
+
+	c.send(r.key, "g", floatValue(float64(size)), r.tags)
+}
+
+func (r *RegisteredSizeGauge) value() float64 {
+	return float64(r.size())
+}
@@ -0,0 +1,73 @@
+package statsd
+
+import (
+	"strconv"
+	"time"
+)
+
+// Standard names of the metrics of service level indicators, tagged with the SLI name.
+const (
+	SLITotal         = "sli.total"
+	SLIGood          = "sli.good"
+	SLILatencyBucket = "sli.latency_bucket"
+
+	TagSLI = "sli"
+	// TagLE is the upper bound of a latency bucket in milliseconds, or "+Inf".
+	TagLE = "le"
+)
+
+// Objectives define what a good event of an SLI is.
+type Objectives struct {
+	// Latency is the maximum duration of a good event. Zero means any duration is good.
+	Latency time.Duration
+	// Buckets are the upper bounds of the latency buckets, in increasing order.
+	Buckets []time.Duration
+}
+
+// SLI emits the standard metrics of a service level indicator for SLO tooling: counters of all
+// and of good events, and cumulative latency buckets, so teams share the same metric shapes.
+type SLI struct {
+	client     *Client
+	objectives Objectives
+	tags       []Tag
+	buckets    [][]Tag // Tags of each bucket, followed by the "+Inf" bucket
+}
+
+// SLI returns the indicator with the name and the objectives.
+func (c *Client) SLI(name string, objectives Objectives) *SLI {
+	tags := []Tag{{Key: TagSLI, Value: name}}
+
+	buckets := make([][]Tag, 0, len(objectives.Buckets)+1)
+
+	for _, bound := range objectives.Buckets {
+		le := strconv.FormatFloat(float64(bound)/float64(time.Millisecond), 'f', -1, 64)
+		buckets = append(buckets, []Tag{tags[0], {Key: TagLE, Value: le}})
+	}
+
+	buckets = append(buckets, []Tag{tags[0], {Key: TagLE, Value: "+Inf"}})
+
+	return &SLI{
+		client:     c,
+		objectives: objectives,
+		tags:       tags,
+		buckets:    buckets,
+	}
+}
+
+// Record records an event that took the duration and failed if err isn't nil. Failed events
+// and events slower than the latency objective aren't good.
+func (s *SLI) Record(duration time.Duration, err error) {
+	s.client.Increment(SLITotal, s.tags...)
+
+	if err == nil && (s.objectives.Latency == 0 || duration <= s.objectives.Latency) {
+		s.client.Increment(SLIGood, s.tags...)
+	}
+
+	for i, bound := range s.objectives.Buckets {
+		if duration <= bound {
+			s.client.Increment(SLILatencyBucket, s.buckets[i]...)
+		}
+	}
+
+	s.client.Increment(SLILatencyBucket, s.buckets[len(s.buckets)-1]...)
+}
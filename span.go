@@ -0,0 +1,50 @@
+package statsd
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// parentTagKey is the tag carrying the name of the parent span.
+const parentTagKey = "parent"
+
+// Span measures the duration of an operation and its sub-operations. Finishing a span sends its
+// duration as a timing; child spans are named after their parent ("checkout.payment") and tagged
+// with the parent's name. Spans are safe for concurrent use.
+type Span struct {
+	client   *Client
+	key      string
+	tags     []Tag
+	start    time.Time
+	finished atomic.Bool
+}
+
+// StartSpan starts timing an operation.
+func (c *Client) StartSpan(key string, tags ...Tag) *Span {
+	return &Span{
+		client:   c,
+		key:      key,
+		tags:     tags,
+		start:    time.Now(),
+		finished: atomic.Bool{},
+	}
+}
+
+// Child starts timing a sub-operation of the span. The child inherits the span's tags.
+func (s *Span) Child(name string, tags ...Tag) *Span {
+	childTags := make([]Tag, 0, len(s.tags)+len(tags)+1)
+	childTags = append(childTags, s.tags...)
+	childTags = append(childTags, tags...)
+	childTags = append(childTags, Tag{Key: parentTagKey, Value: s.key})
+
+	return s.client.StartSpan(s.key+"."+name, childTags...)
+}
+
+// Finish sends the duration of the span. Only the first call has an effect.
+func (s *Span) Finish() {
+	if s.finished.Swap(true) {
+		return
+	}
+
+	s.client.Timing(s.key, time.Since(s.start), s.tags...)
+}
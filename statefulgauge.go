@@ -0,0 +1,77 @@
+package statsd
+
+import "sync/atomic"
+
+// StatefulGauge is a gauge holding its value locally, changed by increments and decrements from any
+// number of goroutines, e.g. the size of a pool. Its absolute value is emitted once per flush interval,
+// avoiding the pitfalls of signed gauge deltas, which StatsD applies to whatever value it last saw.
+// Stateful gauges never expire.
+type StatefulGauge struct {
+	instrument instrument // Not embedded, as stateful gauges don't expire
+
+	current atomic.Int64
+}
+
+// StatefulGauge returns the stateful gauge registered under the key and tags, registering it if needed.
+// A new gauge starts at 0. Stateful gauges are registered apart from registered gauges with the same key and tags.
+func (c *Client) StatefulGauge(key string, tags ...Tag) *StatefulGauge {
+	inst := c.instruments.register(c, "stateful", "g", key, tags, func() registered {
+		return new(StatefulGauge)
+	})
+
+	g := inst.(*StatefulGauge)
+	g.instrument.SetTTL(0)
+
+	return g
+}
+
+// Inc increases the gauge by 1.
+func (g *StatefulGauge) Inc() {
+	g.current.Add(1)
+}
+
+// Dec decreases the gauge by 1.
+func (g *StatefulGauge) Dec() {
+	g.current.Add(-1)
+}
+
+// Add changes the gauge by delta, which may be negative.
+func (g *StatefulGauge) Add(delta int64) {
+	g.current.Add(delta)
+}
+
+// Set sets the gauge to value.
+func (g *StatefulGauge) Set(value int64) {
+	g.current.Store(value)
+}
+
+// Value returns the current value of the gauge.
+func (g *StatefulGauge) Value() int64 {
+	return g.current.Load()
+}
+
+// Describe documents the gauge with a description and a unit, see RegisteredGauge.Describe.
+func (g *StatefulGauge) Describe(description, unit string) {
+	g.instrument.Describe(description, unit)
+}
+
+func (g *StatefulGauge) base() *instrument {
+	return &g.instrument
+}
+
+// collect sends the value, setting negative values with a zero first, as GaugeAbsolute does,
+// so they aren't read as changes.
+func (g *StatefulGauge) collect(c *Client) {
+	v := g.current.Load()
+	if v >= 0 {
+		c.send(g.instrument.key, "g", intValue(v), g.instrument.tags)
+
+		return
+	}
+
+	c.sendValues(nil, g.instrument.key, "g", g.instrument.tags, floatValue(0), floatValue(float64(v)))
+}
+
+func (g *StatefulGauge) value() float64 {
+	return float64(g.current.Load())
+}
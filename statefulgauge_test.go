@@ -0,0 +1,69 @@
+package statsd_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/devem-tech/statsd"
+)
+
+func TestStatefulGaugeNegative(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := statsd.New(statsd.Writer(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := client.StatefulGauge("pool")
+	g.Add(-3)
+	client.Close()
+
+	if got, want := buf.String(), "pool:0|g\npool:-3|g"; got != want {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestStatefulGaugePositive(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := statsd.New(statsd.Writer(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := client.StatefulGauge("pool")
+	g.Inc()
+	g.Add(2)
+	client.Close()
+
+	if got, want := buf.String(), "pool:3|g"; got != want {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestStatefulGaugeSnapshotType(t *testing.T) {
+	client, err := statsd.New(statsd.Writer(io.Discard), statsd.CallerDriven(), statsd.InstrumentTTL(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	client.StatefulGauge("pool").Set(3)
+	client.RegisterGauge("pool").Set(2)
+
+	now := time.Now()
+	client.Tick(now)
+
+	// Idle intervals expire the registered gauge only
+	for range 3 {
+		now = now.Add(time.Hour)
+		client.Tick(now)
+	}
+
+	if snapshot := client.Snapshot(); len(snapshot) != 1 || snapshot[0].Type != "g" || snapshot[0].Value != 3 {
+		t.Errorf("snapshot %+v, want the stateful gauge", snapshot)
+	}
+}
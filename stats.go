@@ -0,0 +1,158 @@
+package statsd
+
+import "sync/atomic"
+
+// Stats holds the lifetime totals of a client.
+type Stats struct {
+	// Metrics is the number of metrics added to the buffer.
+	Metrics uint64
+	// Bytes is the number of bytes written to the connection.
+	Bytes uint64
+	// Datagrams is the number of payloads written to the connection.
+	Datagrams uint64
+	// Dropped is the number of metrics that were rejected or lost in failed writes.
+	Dropped uint64
+	// Errors is the number of errors reported to the error handler.
+	Errors uint64
+	// SlowCallbacks is the number of callbacks that exceeded their time budget or panicked.
+	// See the IsolateCallbacks option.
+	SlowCallbacks uint64
+	// DroppedCallbacks is the number of error handler calls dropped because the handler fell behind.
+	DroppedCallbacks uint64
+}
+
+// stats accumulates the lifetime totals of a client.
+type stats struct {
+	metrics          atomic.Uint64
+	bytes            atomic.Uint64
+	datagrams        atomic.Uint64
+	dropped          atomic.Uint64
+	errors           atomic.Uint64
+	slowCallbacks    atomic.Uint64
+	droppedCallbacks atomic.Uint64
+}
+
+// Stats returns the lifetime totals of the client.
+func (c *Client) Stats() Stats {
+	return Stats{
+		Metrics:          c.stats.metrics.Load(),
+		Bytes:            c.stats.bytes.Load(),
+		Datagrams:        c.stats.datagrams.Load(),
+		Dropped:          c.stats.dropped.Load(),
+		Errors:           c.stats.errors.Load(),
+		SlowCallbacks:    c.stats.slowCallbacks.Load(),
+		DroppedCallbacks: c.stats.droppedCallbacks.Load(),
+	}
+}
+
+// reportError counts the error and passes it to the error channel and the error handler, if any.
+func (c *Client) reportError(err error) {
+	c.stats.errors.Add(1)
+
+	if c.errorCounter != nil {
+		c.errorCounter.add(err)
+	}
+
+	if c.errors != nil {
+		pushDropOldest(c.errors, err)
+	}
+
+	switch {
+	case c.errorHandler == nil:
+	case c.callbacks != nil:
+		c.callbacks.submit(func() {
+			c.errorHandler(err)
+		})
+	default:
+		callErrorHandler(c.errorHandler, err)
+	}
+}
+
+// callErrorHandler calls the error handler, recovering from its panics so they can't kill the flusher.
+func callErrorHandler(handler func(error), err error) {
+	defer func() {
+		_ = recover()
+	}()
+
+	handler(err)
+}
+
+// pushDropOldest sends the error to the channel, dropping the oldest errors if it's full.
+func pushDropOldest(ch chan error, err error) {
+	for {
+		select {
+		case ch <- err:
+			return
+		default:
+		}
+
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
+
+// Errors returns a channel receiving the errors of the client, as an alternative to the error
+// handler for applications using select loops. It is enabled by the ErrorChannel option and
+// is closed by Close; otherwise it is nil. When the channel is full, the oldest errors are dropped.
+func (c *Client) Errors() <-chan error {
+	return c.errors
+}
+
+// SinkStats holds the lifetime totals of writes to a destination of the metrics.
+type SinkStats struct {
+	// Bytes is the number of bytes written to the destination.
+	Bytes uint64
+	// Datagrams is the number of payloads written to the destination.
+	Datagrams uint64
+	// Dropped is the number of metrics lost in failed writes to the destination.
+	Dropped uint64
+	// Errors is the number of failed writes to the destination.
+	Errors uint64
+}
+
+// sinkStats accumulates the lifetime totals of writes to a destination.
+type sinkStats struct {
+	bytes     atomic.Uint64
+	datagrams atomic.Uint64
+	dropped   atomic.Uint64
+	errors    atomic.Uint64
+}
+
+// written records a successful write of the datagram.
+func (s *sinkStats) written(datagram []byte) {
+	s.bytes.Add(uint64(len(datagram)))
+	s.datagrams.Add(1)
+}
+
+// failed records a failed write, which lost the number of metrics.
+func (s *sinkStats) failed(dropped int) {
+	s.errors.Add(1)
+	s.dropped.Add(uint64(dropped))
+}
+
+// snapshot returns the totals.
+func (s *sinkStats) snapshot() SinkStats {
+	return SinkStats{
+		Bytes:     s.bytes.Load(),
+		Datagrams: s.datagrams.Load(),
+		Dropped:   s.dropped.Load(),
+		Errors:    s.errors.Load(),
+	}
+}
+
+// SinkStats returns the lifetime totals of writes per destination, keyed like Health: by the address
+// of the StatsD server, or by the transport if the client doesn't dial it, and by the name of every
+// mirror (see MirrorTo). Unlike Stats, they don't count metrics rejected before they were written.
+func (c *Client) SinkStats() map[string]SinkStats {
+	stats := map[string]SinkStats{c.destination(): c.sinkStats.snapshot()}
+
+	for _, e := range c.encodings {
+		for _, m := range e.mirrors {
+			stats[m.name] = m.stats.snapshot()
+		}
+	}
+
+	return stats
+}
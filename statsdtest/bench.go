@@ -0,0 +1,95 @@
+// Package statsdtest provides helpers for testing and benchmarking code instrumented with the statsd package.
+package statsdtest
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/devem-tech/statsd"
+)
+
+// NullSink is a writer that discards payloads, only counting them. Use it with the statsd.Writer option
+// to measure the cost of the client itself, without network I/O.
+type NullSink struct {
+	writes atomic.Uint64
+	bytes  atomic.Uint64
+}
+
+// Write discards the payload.
+func (s *NullSink) Write(p []byte) (int, error) {
+	s.writes.Add(1)
+	s.bytes.Add(uint64(len(p)))
+
+	return len(p), nil
+}
+
+// Writes returns the number of payloads written.
+func (s *NullSink) Writes() uint64 {
+	return s.writes.Load()
+}
+
+// Bytes returns the number of bytes written.
+func (s *NullSink) Bytes() uint64 {
+	return s.bytes.Load()
+}
+
+// Generator emits a synthetic workload of counters, gauges and timings cycling through
+// a fixed set of metric names and tag sets, resembling the cardinality of a real application.
+// A Generator is not safe for concurrent use; use one per goroutine.
+type Generator struct {
+	keys []string
+	tags [][]statsd.Tag
+	next int
+}
+
+// NewGenerator returns a generator cycling through the given number of metric names and tag sets,
+// each tag set holding tagsPerMetric tags.
+func NewGenerator(keys, tagSets, tagsPerMetric int) *Generator {
+	g := &Generator{
+		keys: make([]string, max(keys, 1)),
+		tags: make([][]statsd.Tag, max(tagSets, 1)),
+		next: 0,
+	}
+
+	for i := range g.keys {
+		g.keys[i] = "bench.metric" + strconv.Itoa(i)
+	}
+
+	for i := range g.tags {
+		g.tags[i] = make([]statsd.Tag, tagsPerMetric)
+
+		for j := range g.tags[i] {
+			g.tags[i][j] = statsd.Tag{
+				Key:   "tag" + strconv.Itoa(j),
+				Value: "value" + strconv.Itoa(i),
+			}
+		}
+	}
+
+	return g
+}
+
+// Emit sends the next metric of the workload to the client.
+func (g *Generator) Emit(c *statsd.Client) {
+	n := g.next
+	g.next++
+
+	key := g.keys[n%len(g.keys)]
+	tags := g.tags[n%len(g.tags)]
+
+	switch n % 3 {
+	case 0:
+		c.Increment(key, tags...)
+	case 1:
+		c.Gauge(key, float64(n), tags...)
+	default:
+		c.Timing(key, 0, tags...)
+	}
+}
+
+// EmitN sends the next n metrics of the workload to the client.
+func (g *Generator) EmitN(c *statsd.Client, n int) {
+	for range n {
+		g.Emit(c)
+	}
+}
@@ -0,0 +1,130 @@
+package statsdtest
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInjected is the error returned by a FaultSink for failed writes unless Faults.Err is set.
+var ErrInjected = errors.New("statsdtest: injected write error")
+
+// Faults configures the faults a FaultSink injects. Rates are fractions of writes between 0 and 1,
+// and each write suffers at most one fault, checked in the order drop, error, delay.
+type Faults struct {
+	// DropRate is the fraction of payloads silently discarded, as lost datagrams are.
+	DropRate float64
+	// ErrorRate is the fraction of writes failing with Err.
+	ErrorRate float64
+	// Err is the error of failed writes. Defaults to ErrInjected; use e.g. syscall.ECONNREFUSED
+	// or syscall.ENOBUFS to exercise the client's reconnect and retry logic.
+	Err error
+	// DelayRate is the fraction of writes delayed by Delay before being written.
+	DelayRate float64
+	// Delay is the duration delayed writes block for.
+	Delay time.Duration
+	// Seed seeds the decisions, so runs with the same seed and writes inject the same faults.
+	Seed uint64
+}
+
+// FaultSink is a writer injecting faults into the writes to an underlying writer, to verify that an
+// application, or the client's retry logic, copes with a failing metrics pipeline. Use it with the
+// statsd.Writer option. It is safe for concurrent use.
+type FaultSink struct {
+	w io.Writer
+
+	lock   sync.Mutex
+	faults Faults
+	rand   *rand.Rand
+
+	writes  atomic.Uint64
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+	delayed atomic.Uint64
+}
+
+// NewFaultSink returns a sink injecting the faults into the writes to w, which may be nil to discard
+// the payloads that get through.
+func NewFaultSink(w io.Writer, faults Faults) *FaultSink {
+	if w == nil {
+		w = io.Discard
+	}
+
+	s := &FaultSink{
+		w:       w,
+		lock:    sync.Mutex{},
+		faults:  Faults{DropRate: 0, ErrorRate: 0, Err: nil, DelayRate: 0, Delay: 0, Seed: 0},
+		rand:    nil,
+		writes:  atomic.Uint64{},
+		dropped: atomic.Uint64{},
+		failed:  atomic.Uint64{},
+		delayed: atomic.Uint64{},
+	}
+
+	s.SetFaults(faults)
+
+	return s
+}
+
+// SetFaults replaces the faults injected from now on, e.g. to heal the sink in the middle of a test.
+func (s *FaultSink) SetFaults(faults Faults) {
+	if faults.Err == nil {
+		faults.Err = ErrInjected
+	}
+
+	s.lock.Lock()
+	s.faults = faults
+	s.rand = rand.New(rand.NewPCG(faults.Seed, faults.Seed)) //nolint:gosec
+	s.lock.Unlock()
+}
+
+// Write writes the payload to the underlying writer unless a fault is injected.
+func (s *FaultSink) Write(p []byte) (int, error) {
+	s.writes.Add(1)
+
+	s.lock.Lock()
+	faults := s.faults
+	drop := s.rand.Float64() < faults.DropRate
+	fail := s.rand.Float64() < faults.ErrorRate
+	delay := s.rand.Float64() < faults.DelayRate
+	s.lock.Unlock()
+
+	switch {
+	case drop:
+		s.dropped.Add(1)
+
+		return len(p), nil
+	case fail:
+		s.failed.Add(1)
+
+		return 0, faults.Err
+	case delay:
+		s.delayed.Add(1)
+		time.Sleep(faults.Delay)
+	}
+
+	return s.w.Write(p) //nolint:wrapcheck
+}
+
+// Writes returns the number of writes, including the faulty ones.
+func (s *FaultSink) Writes() uint64 {
+	return s.writes.Load()
+}
+
+// Dropped returns the number of payloads discarded.
+func (s *FaultSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Failed returns the number of writes that returned an error.
+func (s *FaultSink) Failed() uint64 {
+	return s.failed.Load()
+}
+
+// Delayed returns the number of writes that were delayed.
+func (s *FaultSink) Delayed() uint64 {
+	return s.delayed.Load()
+}
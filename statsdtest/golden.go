@@ -0,0 +1,125 @@
+package statsdtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv is the environment variable that makes AssertGolden write the golden files
+// instead of comparing against them when set to a non-empty value, e.g. STATSDTEST_UPDATE=1 go test.
+const UpdateGoldenEnv = "STATSDTEST_UPDATE"
+
+// Normalize returns the metric lines of a stream, as written by the client or recorded by statsd.Capture,
+// in a canonical form for comparisons: sorted, without empty lines, without the capture timestamps
+// and without DogStatsD "|T" timestamps, so streams compare equal regardless of flush order and time.
+func Normalize(stream []byte) []string {
+	var lines []string
+
+	for _, line := range bytes.FieldsFunc(stream, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		lines = append(lines, normalizeLine(string(line)))
+	}
+
+	slices.Sort(lines)
+
+	return lines
+}
+
+// normalizeLine strips the timestamps off a line.
+func normalizeLine(line string) string {
+	// Capture prefixes lines with "<unix nanoseconds> "
+	if ts, rest, ok := strings.Cut(line, " "); ok && ts != "" && strings.Trim(ts, "0123456789") == "" {
+		line = rest
+	}
+
+	fields := strings.Split(line, "|")
+
+	return strings.Join(slices.DeleteFunc(fields, func(field string) bool {
+		return len(field) > 1 && field[0] == 'T' && strings.Trim(field[1:], "0123456789") == ""
+	}), "|")
+}
+
+// Diff compares two streams after normalizing them, returning a description of the lines missing from
+// got and the unexpected ones, or an empty string if they are equal. Duplicate lines are counted.
+func Diff(want, got []byte) string {
+	w, g := Normalize(want), Normalize(got)
+
+	var missing, unexpected []string
+
+	for len(w) > 0 || len(g) > 0 {
+		switch {
+		case len(g) == 0 || (len(w) > 0 && w[0] < g[0]):
+			missing = append(missing, w[0])
+			w = w[1:]
+		case len(w) == 0 || g[0] < w[0]:
+			unexpected = append(unexpected, g[0])
+			g = g[1:]
+		default:
+			w, g = w[1:], g[1:]
+		}
+	}
+
+	var b strings.Builder
+
+	for _, line := range missing {
+		b.WriteString("- " + line + "\n")
+	}
+
+	for _, line := range unexpected {
+		b.WriteString("+ " + line + "\n")
+	}
+
+	return b.String()
+}
+
+// AssertGolden compares the stream against the golden file at path, failing the test with the
+// differing lines. When the UpdateGoldenEnv environment variable is set, it writes the normalized
+// stream to the golden file instead, creating its directory if needed.
+func AssertGolden(tb testing.TB, path string, got []byte) {
+	tb.Helper()
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := writeGolden(path, got); err != nil {
+			tb.Fatalf("statsdtest: updating golden file: %v", err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		tb.Fatalf("statsdtest: golden file %s doesn't exist, run the test with %s=1 to create it", path, UpdateGoldenEnv)
+	} else if err != nil {
+		tb.Fatalf("statsdtest: reading golden file: %v", err)
+	}
+
+	if diff := Diff(want, got); diff != "" {
+		tb.Errorf("statsdtest: metrics differ from %s (-want +got):\n%s", path, diff)
+	}
+}
+
+// writeGolden writes the normalized stream to the golden file at path.
+func writeGolden(path string, stream []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:mnd
+		return fmt.Errorf("statsdtest: %w", err)
+	}
+
+	var b bytes.Buffer
+
+	for _, line := range Normalize(stream) {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil { //nolint:gosec,mnd
+		return fmt.Errorf("statsdtest: %w", err)
+	}
+
+	return nil
+}
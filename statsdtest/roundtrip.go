@@ -0,0 +1,124 @@
+package statsdtest
+
+import (
+	"errors"
+	"fmt"
+	randv1 "math/rand"
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+
+	"github.com/devem-tech/statsd"
+)
+
+// errRoundTrip is returned when a line doesn't survive a round trip.
+var errRoundTrip = errors.New("statsdtest: round trip mismatch")
+
+// lineTypes are the metric types of random lines.
+var lineTypes = [...]string{"c", "g", "ms", "h", "d", "s"} //nolint:gochecknoglobals
+
+// identBytes are the bytes of names, tag keys and tag values of random lines.
+const identBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-"
+
+// RandomLine returns a random valid metric line for the tag format, e.g. for fuzz or property tests
+// of custom serializers. Timestamps are only set for DogStatsDTags, the only format supporting them.
+func RandomLine(r *rand.Rand, format statsd.TagFormat) statsd.Line {
+	l := statsd.Line{
+		Name:      randomIdent(r),
+		Value:     strconv.Itoa(r.IntN(1000)), //nolint:mnd
+		Type:      lineTypes[r.IntN(len(lineTypes))],
+		Rate:      1,
+		Tags:      nil,
+		Timestamp: 0,
+	}
+
+	for range r.IntN(3) { //nolint:mnd
+		l.Name += "." + randomIdent(r)
+	}
+
+	if r.IntN(2) == 0 {
+		l.Value = strconv.FormatFloat(r.NormFloat64()*100, 'f', -1, 64) //nolint:mnd
+	}
+
+	if r.IntN(4) == 0 { //nolint:mnd
+		l.Rate = float64(1+r.IntN(99)) / 100 //nolint:mnd
+	}
+
+	for range r.IntN(4) { //nolint:mnd
+		l.Tags = append(l.Tags, statsd.Tag{Key: randomIdent(r), Value: randomIdent(r)})
+	}
+
+	if format == statsd.DogStatsDTags && r.IntN(4) == 0 { //nolint:mnd
+		l.Timestamp = 1_600_000_000 + r.Int64N(100_000_000) //nolint:mnd
+	}
+
+	return l
+}
+
+// randomIdent returns a random non-empty identifier.
+func randomIdent(r *rand.Rand) string {
+	b := make([]byte, 1+r.IntN(12)) //nolint:mnd
+	for i := range b {
+		b[i] = identBytes[r.IntN(len(identBytes))]
+	}
+
+	return string(b)
+}
+
+// QuickLine is a statsd.Line in the DogStatsD tag format generated by testing/quick, e.g.
+// quick.Check(func(l statsdtest.QuickLine) bool { ... }, nil).
+type QuickLine statsd.Line
+
+// Generate returns a random line for testing/quick.
+func (QuickLine) Generate(r *randv1.Rand, _ int) reflect.Value {
+	seeded := rand.New(rand.NewPCG(r.Uint64(), r.Uint64())) //nolint:gosec
+
+	return reflect.ValueOf(QuickLine(RandomLine(seeded, statsd.DogStatsDTags)))
+}
+
+// NormalizeLine returns the line as the client writes it after parsing it, e.g. without a sample rate of 1.
+func NormalizeLine(line string, format statsd.TagFormat) (string, error) {
+	l, err := statsd.ParseLine(line, format)
+	if err != nil {
+		return "", fmt.Errorf("statsdtest: %w", err)
+	}
+
+	return string(l.Append(nil, format)), nil
+}
+
+// RoundTrip checks the round-trip property of a line: encoding the parsed line yields the normalized
+// line, and normalizing is idempotent, i.e. Encode(Parse(Encode(Parse(x)))) == Encode(Parse(x)).
+func RoundTrip(line string, format statsd.TagFormat) error {
+	normalized, err := NormalizeLine(line, format)
+	if err != nil {
+		return err
+	}
+
+	again, err := NormalizeLine(normalized, format)
+	if err != nil {
+		return err
+	}
+
+	if again != normalized {
+		return fmt.Errorf("%w: %q normalizes to %q, then to %q", errRoundTrip, line, normalized, again)
+	}
+
+	return nil
+}
+
+// CheckEncoder checks that a custom encoder writes the line the way the client does: parsing its
+// output with the client's parser yields the line. Use it with RandomLine or QuickLine.
+func CheckEncoder(encode func(statsd.Line) []byte, l statsd.Line, format statsd.TagFormat) error {
+	encoded := encode(l)
+
+	parsed, err := statsd.ParseLine(string(encoded), format)
+	if err != nil {
+		return fmt.Errorf("statsdtest: %w", err)
+	}
+
+	if want, got := string(l.Append(nil, format)), string(parsed.Append(nil, format)); want != got {
+		return fmt.Errorf("%w: encoded %q as %q, parsed as %q", errRoundTrip, want, encoded, got)
+	}
+
+	return nil
+}
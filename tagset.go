@@ -0,0 +1,148 @@
+package statsd
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// tagSetKey is the key of a Tag holding a tag set. It starts with a byte no serialized tag contains.
+const tagSetKey = "\x00tagset"
+
+const (
+	// tagSetSeparator separates the sections of a tag set: the tags, then their serialized forms.
+	tagSetSeparator = '\x00'
+	// tagSetTagSeparator separates the tags in the first section of a tag set.
+	tagSetTagSeparator = '\x01'
+	// tagSetValueSeparator separates the key of a tag from its value in the first section of a tag set.
+	tagSetValueSeparator = '\x02'
+)
+
+// NewTagSet returns an immutable set of tags, serialized upfront in every tag format. It is passed to
+// metric calls like a single tag, alone or along with other tags, e.g. c.Increment("x", ts, extra),
+// and its tags are copied into lines without being serialized again. Tag sets may contain tag sets.
+// The returned Tag is opaque: its key and value are internal, and the client returns the tags of
+// a tag set, e.g. from Snapshot and Config, instead of the set itself.
+func NewTagSet(tags ...Tag) Tag {
+	var b strings.Builder
+
+	n := 0
+
+	for tag := range allTags(tags) {
+		if n > 0 {
+			b.WriteByte(tagSetTagSeparator)
+		}
+
+		b.WriteString(tag.Key)
+		b.WriteByte(tagSetValueSeparator)
+		b.WriteString(tag.Value)
+
+		n++
+	}
+
+	if n == 0 {
+		return Tag{Key: tagSetKey, Value: ""}
+	}
+
+	flat := make([]Tag, 0, n)
+	for tag := range allTags(tags) {
+		flat = append(flat, tag)
+	}
+
+	for _, f := range []TagFormat{GraphiteTags, InfluxDBTags, DogStatsDTags} {
+		b.WriteByte(tagSetSeparator)
+		b.Write(f.appendTags(nil, flat, false)[1:]) // Without the leading separator
+	}
+
+	return Tag{Key: tagSetKey, Value: b.String()}
+}
+
+// tagSetSection returns the section of the tag set value with the index, 0 for the tags and 1 plus
+// the tag format for the tags serialized in the format.
+func tagSetSection(set string, index int) string {
+	for range index {
+		i := strings.IndexByte(set, tagSetSeparator)
+		if i < 0 {
+			return ""
+		}
+
+		set = set[i+1:]
+	}
+
+	if i := strings.IndexByte(set, tagSetSeparator); i >= 0 {
+		return set[:i]
+	}
+
+	return set
+}
+
+// allTags returns the tags with the tag sets among them expanded.
+func allTags(tags []Tag) iter.Seq[Tag] {
+	return func(yield func(Tag) bool) {
+		for _, tag := range tags {
+			if tag.Key != tagSetKey {
+				if !yield(tag) {
+					return
+				}
+
+				continue
+			}
+
+			for _, t := range strings.Split(tagSetSection(tag.Value, 0), string(tagSetTagSeparator)) {
+				if t == "" {
+					continue
+				}
+
+				key, value, _ := strings.Cut(t, string(tagSetValueSeparator))
+
+				if !yield(Tag{Key: key, Value: value}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// expandTags returns the tags with the tag sets among them expanded, for tags returned to callers,
+// which must not see the internal form of tag sets. Tags without tag sets are returned as they are.
+func expandTags(tags []Tag) []Tag {
+	if !slices.ContainsFunc(tags, func(tag Tag) bool { return tag.Key == tagSetKey }) {
+		return tags
+	}
+
+	return slices.Collect(allTags(tags))
+}
+
+// stripReserved returns the tags without the ones with reserved keys, reporting each of them.
+// Tag sets are expanded if they hold a reserved key.
+func (c *Client) stripReserved(tags []Tag) []Tag {
+	if len(c.reservedTags) == 0 || !c.hasReserved(tags) {
+		return tags
+	}
+
+	kept := make([]Tag, 0, len(tags))
+
+	for tag := range allTags(tags) {
+		if slices.Contains(c.reservedTags, tag.Key) {
+			c.reportError(fmt.Errorf("%w: %q", ErrReservedTag, tag.Key))
+
+			continue
+		}
+
+		kept = append(kept, tag)
+	}
+
+	return kept
+}
+
+// hasReserved reports whether any of the tags has a reserved key.
+func (c *Client) hasReserved(tags []Tag) bool {
+	for tag := range allTags(tags) {
+		if slices.Contains(c.reservedTags, tag.Key) {
+			return true
+		}
+	}
+
+	return false
+}
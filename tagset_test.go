@@ -0,0 +1,31 @@
+package statsd_test
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/devem-tech/statsd"
+)
+
+func TestTagSetsExpandedWhenExposed(t *testing.T) {
+	set := statsd.NewTagSet(statsd.T("region", "eu"), statsd.NewTagSet(statsd.T("zone", "a")))
+	want := []statsd.Tag{statsd.T("region", "eu"), statsd.T("zone", "a"), statsd.T("env", "prod")}
+
+	client, err := statsd.New(statsd.Writer(io.Discard), statsd.Tags([]statsd.Tag{set, statsd.T("env", "prod")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if got := client.Config().Tags; !slices.Equal(got, want) {
+		t.Errorf("config has tags %q, want %q", got, want)
+	}
+
+	client.RegisterCounter("requests", set, statsd.T("env", "prod")).Increment()
+
+	snapshots := client.Snapshot()
+	if len(snapshots) != 1 || !slices.Equal(snapshots[0].Tags, want) {
+		t.Errorf("snapshot is %+v, want tags %q", snapshots, want)
+	}
+}
@@ -0,0 +1,31 @@
+package statsd
+
+import "time"
+
+// Tick drives a client created with the CallerDriven option from the application's event loop.
+// It emits the registered instruments and flushes the queue when a flush interval passed since
+// the last flush by Tick, flushes it early if it filled up meanwhile, and checks for stalls if
+// the watchdog is enabled. Call it at least once per flush interval, from one goroutine at a time.
+func (c *Client) Tick(now time.Time) {
+	last := c.lastTick.Load()
+
+	// The first tick starts the first interval
+	if last == 0 {
+		last = now.UnixNano()
+		c.lastTick.Store(last)
+	}
+
+	switch {
+	case now.UnixNano()-last >= int64(c.flushInterval):
+		c.lastTick.Store(now.UnixNano())
+		c.flushPending.Store(false)
+		c.instruments.collect(c)
+		c.flushMetrics()
+	case c.flushPending.Swap(false):
+		c.flushMetrics()
+	}
+
+	if c.watchdog != nil {
+		c.watchdog.check(c)
+	}
+}
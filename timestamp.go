@@ -0,0 +1,58 @@
+package statsd
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Timestamped sends metrics observed at a given time, e.g. by backfill jobs. Timestamps are only
+// supported by the DogStatsD tag format ("|T" field); with other formats, metrics are sent without
+// their timestamp and a warning is logged.
+type Timestamped struct {
+	client *Client
+	time   time.Time
+}
+
+// WithTimestamp returns a sender of metrics observed at t.
+func (c *Client) WithTimestamp(t time.Time) Timestamped {
+	return Timestamped{
+		client: c,
+		time:   t,
+	}
+}
+
+// Count sends a counter.
+func (t Timestamped) Count(key string, value int64, tags ...Tag) {
+	if value == 0 {
+		return
+	}
+
+	t.send(key, "c", intValue(value), tags)
+}
+
+// Increment increases a counter by 1.
+func (t Timestamped) Increment(key string, tags ...Tag) {
+	t.Count(key, 1, tags...)
+}
+
+// Gauge sends a gauge.
+func (t Timestamped) Gauge(key string, value float64, tags ...Tag) {
+	t.send(key, "g", floatValue(value), tags)
+}
+
+// Timing sends a timer.
+func (t Timestamped) Timing(key string, duration time.Duration, tags ...Tag) {
+	t.send(key, "ms", durationValue(duration), tags)
+}
+
+// send queues the metric with the timestamp if the tag format supports it.
+func (t Timestamped) send(key, mt string, v value, tags []Tag) {
+	if t.client.serializer.tagFormat == DogStatsDTags {
+		v = v.at(t.time)
+	} else {
+		t.client.logger.log(slog.LevelWarn, "timestamps require the DogStatsD tag format, dropped the timestamp",
+			slog.String("key", key))
+	}
+
+	t.client.send(key, mt, v, tags)
+}
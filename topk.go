@@ -0,0 +1,128 @@
+package statsd
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// RegisteredTopK tracks the K items with the largest values observed during a flush interval,
+// e.g. the slowest endpoints or the largest payloads, and emits each as a gauge of its largest
+// value, tagged with the item. It is a cheap view of the worst offenders without histograms.
+type RegisteredTopK struct {
+	instrument
+
+	lock  sync.Mutex
+	label string
+	k     int
+	items topItems
+}
+
+// topItem is an item and the largest value observed for it.
+type topItem struct {
+	item  string
+	value float64
+}
+
+// topItems is a min-heap of items by value.
+type topItems struct {
+	items []topItem
+	index map[string]int // Item to its position in items
+}
+
+func (h *topItems) Len() int           { return len(h.items) }
+func (h *topItems) Less(i, j int) bool { return h.items[i].value < h.items[j].value }
+
+func (h *topItems) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].item] = i
+	h.index[h.items[j].item] = j
+}
+
+func (h *topItems) Push(x any) {
+	item := x.(topItem) //nolint:forcetypeassert
+	h.index[item.item] = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *topItems) Pop() any {
+	item := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.index, item.item)
+
+	return item
+}
+
+// RegisterTopK returns the top-K instrument registered under the key and tags, registering it
+// if needed. Items are tagged with the label as key, e.g. "endpoint".
+func (c *Client) RegisterTopK(key string, k int, label string, tags ...Tag) *RegisteredTopK {
+	inst := c.instruments.register(c, "topk", "topk", key, tags, func() registered {
+		t := new(RegisteredTopK)
+		t.label = label
+		t.k = k
+		t.items = topItems{items: make([]topItem, 0, k), index: make(map[string]int, k)}
+
+		return t
+	})
+
+	return inst.(*RegisteredTopK)
+}
+
+// Observe records a value of the item. Only the K items with the largest values are kept.
+func (r *RegisteredTopK) Observe(item string, value float64) {
+	r.lock.Lock()
+
+	switch i, ok := r.items.index[item]; {
+	case ok:
+		if value > r.items.items[i].value {
+			r.items.items[i].value = value
+			heap.Fix(&r.items, i)
+		}
+	case r.items.Len() < r.k:
+		heap.Push(&r.items, topItem{item: item, value: value})
+	case r.k > 0 && value > r.items.items[0].value:
+		delete(r.items.index, r.items.items[0].item)
+		r.items.items[0] = topItem{item: item, value: value}
+		r.items.index[item] = 0
+		heap.Fix(&r.items, 0)
+	}
+
+	r.lock.Unlock()
+
+	r.client.instruments.touch(r)
+}
+
+func (r *RegisteredTopK) base() *instrument {
+	return &r.instrument
+}
+
+func (r *RegisteredTopK) collect(c *Client) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	tags := getTags()
+	defer putTags(tags)
+
+	for _, item := range r.items.items {
+		*tags = append(append((*tags)[:0], r.tags...), Tag{Key: r.label, Value: item.item})
+
+		c.send(r.key, "g", floatValue(item.value), *tags)
+	}
+
+	r.items.items = r.items.items[:0]
+	clear(r.items.index)
+}
+
+func (r *RegisteredTopK) value() float64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	largest := 0.0
+
+	for i, item := range r.items.items {
+		if i == 0 || item.value > largest {
+			largest = item.value
+		}
+	}
+
+	return largest
+}
@@ -0,0 +1,100 @@
+package statsd
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxCachedTagKeys bounds the number of tag keys the typed tag constructors remember as validated.
+const maxCachedTagKeys = 1024
+
+// tagKeyCache maps the tag keys passed to the typed tag constructors to their validated form.
+var tagKeyCache = struct { //nolint:gochecknoglobals
+	keys sync.Map
+	size atomic.Int64
+}{
+	keys: sync.Map{},
+	size: atomic.Int64{},
+}
+
+// T returns a tag with a string value of any string type, e.g. a typed enum. Like the other typed
+// constructors, bytes that would break the tag, i.e. unsafe bytes, spaces and the tag separators
+// ';', ',', '=' and '#', are replaced with underscores in the key, which is validated only once.
+func T[V ~string](key string, value V) Tag {
+	return Tag{Key: tagKey(key), Value: string(value)}
+}
+
+// IntTag returns a tag with a decimal integer value. Values are interned, so formatting them
+// only allocates the first time.
+func IntTag[V ~int | ~int8 | ~int16 | ~int32 | ~int64](key string, value V) Tag {
+	var buf [20]byte
+
+	return Tag{Key: tagKey(key), Value: InternBytes(strconv.AppendInt(buf[:0], int64(value), 10))}
+}
+
+// UintTag returns a tag with a decimal unsigned integer value. Values are interned, so formatting
+// them only allocates the first time.
+func UintTag[V ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](key string, value V) Tag {
+	var buf [20]byte
+
+	return Tag{Key: tagKey(key), Value: InternBytes(strconv.AppendUint(buf[:0], uint64(value), 10))}
+}
+
+// FloatTag returns a tag with the shortest decimal representation of a floating-point value.
+func FloatTag[V ~float32 | ~float64](key string, value V) Tag {
+	var buf [32]byte
+
+	return Tag{Key: tagKey(key), Value: InternBytes(strconv.AppendFloat(buf[:0], float64(value), 'f', -1, 64))}
+}
+
+// BoolTag returns a tag with the value "true" or "false".
+func BoolTag(key string, value bool) Tag {
+	return Tag{Key: tagKey(key), Value: strconv.FormatBool(value)}
+}
+
+// DurationTag returns a tag with a duration value formatted like time.Duration, e.g. "1m30s".
+// Durations make high-cardinality tags unless they are bucketed, e.g. with Round.
+func DurationTag(key string, value time.Duration) Tag {
+	return Tag{Key: tagKey(key), Value: Intern(value.String())}
+}
+
+// tagKey returns the key with the bytes a tag key doesn't allow replaced with underscores.
+func tagKey(key string) string {
+	if validated, ok := tagKeyCache.keys.Load(key); ok {
+		return validated.(string) //nolint:forcetypeassert
+	}
+
+	validated := key
+
+	for i := range len(key) {
+		if !tagKeyByte(key[i]) {
+			buf := []byte(key)
+
+			for j, b := range buf {
+				if !tagKeyByte(b) {
+					buf[j] = '_'
+				}
+			}
+
+			validated = string(buf)
+
+			break
+		}
+	}
+
+	if tagKeyCache.size.Load() < maxCachedTagKeys {
+		if _, loaded := tagKeyCache.keys.LoadOrStore(key, validated); !loaded {
+			tagKeyCache.size.Add(1)
+		}
+	}
+
+	return validated
+}
+
+// tagKeyByte reports whether b may appear in the key of a typed tag. Unlike in name segments,
+// dots are allowed, e.g. "http.method".
+func tagKeyByte(b byte) bool {
+	return b == '.' || segmentByte(b)
+}
@@ -0,0 +1,32 @@
+package statsd
+
+// TypeNames holds a name segment per metric type, used to namespace metrics by their type.
+// Empty segments leave metrics of the type unchanged.
+type TypeNames struct {
+	Counter string
+	Gauge   string
+	Timer   string
+}
+
+// LegacyTypeSuffixes are the type suffixes used by many older StatsD clients.
+func LegacyTypeSuffixes() TypeNames {
+	return TypeNames{
+		Counter: "count",
+		Gauge:   "gauge",
+		Timer:   "timer",
+	}
+}
+
+// forType returns the segment for the metric type.
+func (n TypeNames) forType(mt string) string {
+	switch mt {
+	case "c":
+		return n.Counter
+	case "g":
+		return n.Gauge
+	case "ms":
+		return n.Timer
+	}
+
+	return ""
+}
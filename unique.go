@@ -0,0 +1,83 @@
+package statsd
+
+import (
+	"sync"
+	"time"
+)
+
+// maxUniqueEntries bounds the number of values UniqueInWindow remembers per client.
+const maxUniqueEntries = 1 << 16
+
+// uniques remembers until when the values sent by UniqueInWindow are known to the server.
+type uniques struct {
+	lock    sync.Mutex
+	expires map[string]map[string]int64 // Key to value to expiry in Unix nanoseconds
+	size    int
+}
+
+// UniqueInWindow adds the value to the set with the key, like Set, unless it was already sent within
+// the window, so streams with many duplicates, e.g. user IDs of requests, cost a fraction of the traffic.
+// The window should not exceed the flush interval of the server, or values are undercounted. Up to
+// 65536 values are remembered; beyond that, expired values are forgotten first, then all of them.
+func (c *Client) UniqueInWindow(key, value string, window time.Duration) {
+	now := time.Now().UnixNano()
+
+	if !c.uniques.admit(key, value, now, now+int64(window)) {
+		return
+	}
+
+	c.Set(key, value)
+}
+
+// admit reports whether the value must be sent, remembering it until expiry if so.
+func (u *uniques) admit(key, value string, now, expiry int64) bool {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	values := u.expires[key]
+	if exp, ok := values[value]; ok && exp > now {
+		return false
+	}
+
+	if u.size >= maxUniqueEntries {
+		u.sweep(now)
+	}
+
+	if values == nil {
+		if u.expires == nil {
+			u.expires = make(map[string]map[string]int64)
+		}
+
+		values = make(map[string]int64)
+		u.expires[key] = values
+	}
+
+	if _, ok := values[value]; !ok {
+		u.size++
+	}
+
+	values[value] = expiry
+
+	return true
+}
+
+// sweep forgets the expired values, or all values if none expired.
+func (u *uniques) sweep(now int64) {
+	for key, values := range u.expires {
+		for value, exp := range values {
+			if exp <= now {
+				delete(values, value)
+				u.size--
+			}
+		}
+
+		if len(values) == 0 {
+			delete(u.expires, key)
+		}
+	}
+
+	if u.size >= maxUniqueEntries {
+		clear(u.expires)
+		u.size = 0
+	}
+}
@@ -0,0 +1,145 @@
+package statsd
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// watchdog detects a stuck background flusher, e.g. one blocked by a wedged sink.
+type watchdog struct {
+	stall     time.Duration
+	limit     int
+	heartbeat atomic.Int64 // Unix nanoseconds of the last completed flush
+	reported  atomic.Bool
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// newWatchdog returns a watchdog considering the flusher stuck when it doesn't complete a flush
+// for longer than stall, allowing at most limit queued bytes meanwhile.
+func newWatchdog(stall time.Duration, limit int) *watchdog {
+	w := &watchdog{
+		stall:     stall,
+		limit:     limit,
+		heartbeat: atomic.Int64{},
+		reported:  atomic.Bool{},
+		quit:      make(chan struct{}),
+		wg:        sync.WaitGroup{},
+	}
+
+	w.beat()
+
+	return w
+}
+
+// start runs the watchdog in the background, reporting stalls of the client's flusher.
+func (w *watchdog) start(c *Client) {
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(w.stall / 2) //nolint:mnd
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.check(c)
+			case <-w.quit:
+				return
+			}
+		}
+	}()
+}
+
+// check reports a stall once per stall episode.
+func (w *watchdog) check(c *Client) {
+	stalledFor := w.stalledFor()
+	if stalledFor <= w.stall {
+		w.reported.Store(false)
+
+		return
+	}
+
+	if w.reported.Swap(true) {
+		return
+	}
+
+	c.logger.log(slog.LevelWarn, "flusher stalled", slog.Duration("stalled_for", stalledFor))
+	c.reportError(fmt.Errorf("%w for %s", ErrFlushStalled, stalledFor))
+}
+
+// stop stops the background watchdog.
+func (w *watchdog) stop() {
+	close(w.quit)
+	w.wg.Wait()
+}
+
+// beat records that a flush completed.
+func (w *watchdog) beat() {
+	w.heartbeat.Store(time.Now().UnixNano())
+}
+
+// stalledFor returns the time since the last completed flush.
+func (w *watchdog) stalledFor() time.Duration {
+	return time.Duration(time.Now().UnixNano() - w.heartbeat.Load())
+}
+
+// overflows reports whether the queue of the given size must shed its oldest metrics.
+func (w *watchdog) overflows(size int) bool {
+	return w.limit > 0 && size > w.limit && w.stalledFor() > w.stall
+}
+
+// dropOldest removes the oldest metrics from the queue until its estimated size is at most half
+// of size, returning the number of metrics removed. High-priority metrics are kept as long as
+// their estimated size is within the reserve.
+func (q *queue) dropOldest(size, reserve int) int {
+	reserved := 0
+
+	for _, m := range q.metrics {
+		if m.priority {
+			reserved += q.metricSize(m)
+		}
+	}
+
+	kept, keptTags := 0, 0
+
+	for _, m := range q.metrics {
+		if q.size > size/2 && (!m.priority || reserved > reserve) { //nolint:mnd
+			n := q.metricSize(m)
+			q.size -= n
+
+			if m.priority {
+				reserved -= n
+			}
+
+			if m.gauge != nil {
+				m.gauge.seal() // Queue the gauge again on its next update
+			}
+
+			continue
+		}
+
+		// Move the tags of kept metrics down over those of dropped ones, which precede them
+		n := copy(q.tags[keptTags:], q.tags[m.tagsFrom:m.tagsTo])
+		m.tagsFrom, m.tagsTo = keptTags, keptTags+n
+		keptTags += n
+
+		q.metrics[kept] = m
+		kept++
+	}
+
+	n := len(q.metrics) - kept
+
+	clear(q.metrics[kept:])
+	q.metrics = q.metrics[:kept]
+
+	clear(q.tags[keptTags:]) // Don't retain the tag strings
+	q.tags = q.tags[:keptTags]
+
+	return n
+}
@@ -0,0 +1,37 @@
+package statsd
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestDropOldestCompactsTags(t *testing.T) {
+	q := newQueue(0)
+
+	for i := range 1000 {
+		tag := Tag{Key: "i", Value: strconv.Itoa(i)}
+		q.push(nil, "key", "c", intValue(1), []Tag{tag, {Key: "priority", Value: strconv.Itoa(i % 10)}}, i%10 == 0)
+
+		if q.size > 4096 {
+			q.dropOldest(4096, 512)
+		}
+	}
+
+	if len(q.tags) != 2*len(q.metrics) {
+		t.Fatalf("%d tags for %d metrics", len(q.tags), len(q.metrics))
+	}
+
+	for _, m := range q.metrics {
+		tags := q.tags[m.tagsFrom:m.tagsTo]
+		i, err := strconv.Atoi(tags[0].Value)
+
+		if err != nil || tags[1].Value != strconv.Itoa(i%10) || m.priority != (i%10 == 0) {
+			t.Errorf("metric has tags %v", tags)
+		}
+	}
+
+	if !slices.IsSortedFunc(q.metrics, func(a, b metric) int { return a.tagsFrom - b.tagsFrom }) {
+		t.Error("metrics out of order")
+	}
+}